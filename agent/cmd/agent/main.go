@@ -2,70 +2,204 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 	"time"
 
+	"github.com/fabricore/agent/internal/config"
+	"github.com/fabricore/agent/internal/diagnostic"
+	"github.com/fabricore/agent/internal/logging"
 	"github.com/fabricore/agent/internal/mcp"
 	"github.com/fabricore/agent/internal/orchestrator"
 	"github.com/fabricore/agent/internal/security"
 	"github.com/fabricore/agent/internal/sys"
+	"github.com/fabricore/agent/internal/version"
 )
 
+// reconnectResetThreshold is how long a connection must stay up before a
+// later failure is treated as a fresh problem rather than a continuation of
+// the run that's already backing off.
+const reconnectResetThreshold = 30 * time.Second
+
 func main() {
+	// Must run before any other startup work: if this process was re-exec'd
+	// as a security.CommandPolicy resource-limit trampoline, it applies the
+	// requested rlimits to itself and execve's into the real target, never
+	// returning. See security.CommandPolicy.PrepareExec for why that can't
+	// happen after cmd.Start() instead.
+	security.RunExecWrapperIfInvoked()
+
 	// Immediate startup message
 	fmt.Println("╔═══════════════════════════════════════════╗")
-	fmt.Println("║       FabriCore Agent v0.1.0              ║")
+	fmt.Printf("║       FabriCore Agent v%-18s ║\n", version.Version)
 	fmt.Println("╚═══════════════════════════════════════════╝")
 
 	serverURL := flag.String("server", "ws://localhost:8000/api/v1/ws", "Server WebSocket URL")
 	token := flag.String("token", "", "Authentication Token")
+	tlsAllowedOrigins := flag.String("tls-allowed-origins", "", "Comma-separated list of server hosts this agent may dial (default: no restriction)")
+	tlsPinSHA256 := flag.String("tls-pin-sha256", "", "Expected hex SHA-256 fingerprint of the server's leaf TLS certificate")
+	tlsCABundle := flag.String("tls-ca-bundle", "", "Path to a PEM CA bundle to verify the server certificate against, instead of the system roots")
+	pidFile := flag.String("pid-file", "", "Write the agent's PID to this file so operators can signal it for graceful reload")
+	backoffInitial := flag.Duration("backoff-initial", config.DefaultRetryPolicy().BaseDelay, "Initial delay before the first reconnect attempt")
+	backoffMax := flag.Duration("backoff-max", config.DefaultRetryPolicy().MaxDelay, "Maximum delay between reconnect attempts, before jitter is applied")
+	backoffMultiplier := flag.Float64("backoff-multiplier", config.DefaultRetryPolicy().Multiplier, "Factor the reconnect delay is multiplied by after each failed attempt")
+	backoffJitter := flag.Float64("backoff-jitter", config.DefaultRetryPolicy().Jitter, "Fraction of the reconnect delay to randomize, in [0, 1]")
+	retryLimit := flag.Int("retry-limit", 0, "Maximum number of consecutive reconnect attempts before giving up (default: unlimited)")
+	diagAddr := flag.String("diag-addr", "", "Address to serve the diagnostic endpoint (/healthz, /readyz, /metrics, /debug/pprof, /version) on, e.g. 127.0.0.1:7001 (default: disabled)")
+	commandPolicyFile := flag.String("command-policy-file", "", "Path to a signed YAML command allowlist for exec_command (default: unrestricted)")
+	commandPolicySig := flag.String("command-policy-sig", "", "Path to the hex-encoded ed25519 signature of --command-policy-file")
+	commandPolicyPubKey := flag.String("command-policy-pubkey", "", "Path to the hex-encoded ed25519 public key that signed --command-policy-file")
+	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, or error")
+	logFormat := flag.String("log-format", "text", "Log output format: text or json")
+	logFile := flag.String("log-file", "", "Write logs to this file instead of stderr (append mode, logrotate copytruncate safe)")
 	flag.Parse()
 
-	log.Println("[INFO] Parsing command line arguments...")
-	log.Printf("[INFO] Server URL: %s", *serverURL)
+	level, err := logging.ParseLevel(*logLevel)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	format, err := logging.ParseFormat(*logFormat)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	logOut := os.Stderr
+	if *logFile != "" {
+		f, err := os.OpenFile(*logFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to open log file %s: %v\n", *logFile, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		logOut = f
+	}
+
+	root := logging.New(format, level, logOut)
+	agentLog := logging.Named(root, "agent")
+	sysLog := logging.Named(root, "agent.sys")
+	mcpLog := logging.Named(root, "agent.mcp")
+	orchLog := logging.Named(root, "agent.orch")
+	secLog := logging.Named(root, "agent.sec")
+
+	agentLog.Info("parsing command line arguments")
+	agentLog.Info("server url", "url", *serverURL)
 	if *token != "" {
-		log.Printf("[INFO] Token: %s***", (*token)[:min(4, len(*token))])
+		agentLog.Info("token loaded", "token_prefix", (*token)[:min(4, len(*token))])
 	}
 
 	if *token == "" {
-		log.Fatal("[ERROR] Token is required. Use --token <your-token>")
+		agentLog.Error("token is required, use --token <your-token>")
+		os.Exit(1)
 	}
 
-	// Setup context with signal handling
-	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
-	defer stop()
+	if err := writePIDFile(*pidFile); err != nil {
+		agentLog.Warn("failed to write pid file", "path", *pidFile, "error", err)
+	}
+	defer removePIDFile(*pidFile, agentLog)
+
+	// Setup a context we cancel ourselves in response to signals, rather
+	// than signal.NotifyContext, since SIGUSR2/SIGHUP/SIGQUIT each need
+	// distinct reload/drain behavior (see watchSignals).
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 
 	// Initialize Components
-	log.Println("[INFO] Initializing components...")
-	systemOps := sys.NewRealSystem()
-	mcpManager := mcp.NewManager()
-	secManager := security.NewManager()
+	agentLog.Info("initializing components")
+
+	var commandPolicy *security.CommandPolicy
+	if *commandPolicyFile != "" {
+		commandPolicy, err = security.LoadCommandPolicy(*commandPolicyFile, *commandPolicySig, *commandPolicyPubKey)
+		if err != nil {
+			fatal(agentLog, *pidFile, "failed to load command policy", "error", err)
+		}
+		agentLog.Info("command policy loaded", "path", *commandPolicyFile, "allowed_commands", len(commandPolicy.Allowlist))
+	}
+
+	systemOps := sys.NewRealSystem(sysLog, commandPolicy)
+	mcpManager := mcp.NewManager(mcpLog)
+	secManager := security.NewManager(secLog)
+
+	var tlsConfig *config.TLSConfig
+	if *tlsAllowedOrigins != "" || *tlsPinSHA256 != "" || *tlsCABundle != "" {
+		tlsConfig = &config.TLSConfig{
+			PinnedSHA256: *tlsPinSHA256,
+			CABundlePath: *tlsCABundle,
+		}
+		if *tlsAllowedOrigins != "" {
+			tlsConfig.AllowedOrigins = strings.Split(*tlsAllowedOrigins, ",")
+		}
+	}
+
+	metrics := diagnostic.NewMetrics()
 
 	// Initialize Orchestrator
-	orch := orchestrator.New(*serverURL, *token, systemOps, mcpManager, secManager)
+	orch := orchestrator.New(*serverURL, *token, systemOps, mcpManager, secManager, tlsConfig, commandPolicy, metrics, orchLog)
+
+	go watchSignals(ctx, cancel, orch, *pidFile, agentLog)
+
+	if *diagAddr != "" {
+		diagLog := logging.Named(root, "agent.diag")
+		diagServer := diagnostic.NewServer(*diagAddr, orch, metrics, diagLog)
+		go diagServer.Start(ctx)
+	}
+
+	retryPolicy := config.RetryPolicy{
+		BaseDelay:  *backoffInitial,
+		MaxDelay:   *backoffMax,
+		Multiplier: *backoffMultiplier,
+		Jitter:     *backoffJitter,
+	}
 
 	// Start Agent with retry logic
-	log.Println("[INFO] Starting agent service loop...")
+	agentLog.Info("starting agent service loop")
+	attempt := 0
 	for {
+		connectedAt := time.Now()
 		err := orch.Start(ctx)
 		if err != nil {
 			if ctx.Err() != nil {
-				log.Println("[INFO] Agent shutting down gracefully.")
+				agentLog.Info("agent shutting down gracefully")
 				break
 			}
-			log.Printf("[ERROR] Agent connection failed: %v", err)
-			log.Println("[INFO] Retrying in 10 seconds...")
+
+			var trustErr *config.TrustError
+			if errors.As(err, &trustErr) {
+				fatal(agentLog, *pidFile, "agent trust policy refused to dial, not retrying", "error", err)
+			}
+
+			var authErr *config.AuthError
+			if errors.As(err, &authErr) {
+				fatal(agentLog, *pidFile, "agent credentials rejected by server, not retrying", "error", err)
+			}
+
+			// A connection that stayed up for a while was healthy; reset
+			// the backoff instead of penalizing it for whatever broke it
+			// later.
+			if time.Since(connectedAt) >= reconnectResetThreshold {
+				attempt = 0
+			}
+
+			if *retryLimit > 0 && attempt >= *retryLimit {
+				fatal(agentLog, *pidFile, "retry limit reached, giving up", "attempts", attempt, "retry_limit", *retryLimit)
+			}
+
+			delay := retryPolicy.NextDelay(attempt)
+			attempt++
+			metrics.ReconnectAttempts.Inc()
+			agentLog.Error("agent connection failed", "error", err)
+			agentLog.Info("retrying reconnect", "attempt", attempt, "delay", delay)
 
 			select {
-			case <-time.After(10 * time.Second):
-				log.Println("[INFO] Reconnection attempt...")
+			case <-time.After(delay):
+				agentLog.Info("reconnection attempt")
 			case <-ctx.Done():
-				log.Println("[INFO] Agent shutting down gracefully.")
+				agentLog.Info("agent shutting down gracefully")
 				return
 			}
 		} else {
@@ -73,12 +207,30 @@ func main() {
 			if ctx.Err() != nil {
 				break
 			}
-			log.Println("[WARN] Orchestrator stopped unexpectedly without error. Retrying in 10s...")
-			time.Sleep(10 * time.Second)
+			if *retryLimit > 0 && attempt >= *retryLimit {
+				fatal(agentLog, *pidFile, "retry limit reached, giving up", "attempts", attempt, "retry_limit", *retryLimit)
+			}
+			delay := retryPolicy.NextDelay(attempt)
+			attempt++
+			metrics.ReconnectAttempts.Inc()
+			agentLog.Warn("orchestrator stopped unexpectedly without error, retrying", "attempt", attempt, "delay", delay)
+			time.Sleep(delay)
 		}
 	}
 }
 
+// fatal logs a fatal error and exits with status 1, removing the pid file
+// first. os.Exit skips deferred functions, so every exit point reached
+// after writePIDFile must go through this instead of calling os.Exit
+// directly, or a permanent failure leaves a stale --pid-file on disk (the
+// same reason reload.go's drainAndExit and SIGQUIT path call removePIDFile
+// before their own os.Exit).
+func fatal(logger *slog.Logger, pidFile string, msg string, args ...any) {
+	logger.Error(msg, args...)
+	removePIDFile(pidFile, logger)
+	os.Exit(1)
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/fabricore/agent/internal/orchestrator"
+)
+
+// drainTimeout bounds how long a draining process waits for in-flight tool
+// executions before giving up and exiting anyway.
+const drainTimeout = 30 * time.Second
+
+// watchSignals drives graceful reload and shutdown:
+//   - SIGHUP, plus SIGUSR2 where the platform has one (see
+//     reloadTriggerSignals/isReloadTrigger): fork an exec of the running
+//     binary, which dials its own fresh connection, then drain in-flight
+//     work in this process and exit, leaving the child attached.
+//   - SIGTERM / SIGINT: cancel ctx to stop accepting new work, drain
+//     in-flight tool executions, then exit.
+//   - SIGQUIT: exit immediately without draining.
+//
+// It blocks until the process should exit.
+func watchSignals(ctx context.Context, cancel context.CancelFunc, orch *orchestrator.Orchestrator, pidFile string, logger *slog.Logger) {
+	sigCh := make(chan os.Signal, 1)
+	notify := append([]os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGHUP, syscall.SIGQUIT}, reloadTriggerSignals()...)
+	signal.Notify(sigCh, notify...)
+	defer signal.Stop(sigCh)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigCh:
+			switch {
+			case sig == syscall.SIGQUIT:
+				logger.Warn("SIGQUIT received, exiting immediately without draining")
+				removePIDFile(pidFile, logger)
+				os.Exit(1)
+
+			case sig == syscall.SIGHUP || isReloadTrigger(sig):
+				logger.Info("signal received: forking upgraded agent", "signal", sig.String())
+				if err := relaunch(); err != nil {
+					logger.Error("failed to fork upgraded agent, staying up", "error", err)
+					continue
+				}
+				logger.Info("upgraded agent forked, draining in-flight work before exit")
+				drainAndExit(orch, pidFile, logger)
+
+			case sig == os.Interrupt || sig == syscall.SIGTERM:
+				logger.Info("signal received: draining in-flight work before exit", "signal", sig.String())
+				cancel()
+				drainAndExit(orch, pidFile, logger)
+			}
+		}
+	}
+}
+
+func drainAndExit(orch *orchestrator.Orchestrator, pidFile string, logger *slog.Logger) {
+	drainCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+
+	if n := orch.Tracker().Count(); n > 0 {
+		logger.Info("waiting for in-flight tasks to finish", "count", n, "timeout", drainTimeout)
+	}
+	if err := orch.Tracker().Drain(drainCtx); err != nil {
+		logger.Warn("drain timed out with tasks still running", "count", orch.Tracker().Count(), "error", err)
+	}
+
+	removePIDFile(pidFile, logger)
+	os.Exit(0)
+}
+
+// relaunch starts a new copy of the running binary with the same arguments.
+// The child dials its own fresh connection under a newly generated agentID;
+// the old process keeps serving in-flight work until drainAndExit finishes.
+func relaunch() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("resolving current executable: %w", err)
+	}
+
+	attr := &os.ProcAttr{
+		Env:   os.Environ(),
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr},
+	}
+	_, err = os.StartProcess(exe, os.Args, attr)
+	return err
+}
+
+func writePIDFile(path string) error {
+	if path == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644)
+}
+
+func removePIDFile(path string, logger *slog.Logger) {
+	if path == "" {
+		return
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		logger.Warn("failed to remove pid file", "path", path, "error", err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadTriggerSignals are the additional signals, beyond SIGHUP,
+// watchSignals listens for to trigger a fork-and-drain reload. SIGUSR2 has
+// no Windows equivalent (see reload_windows.go), so it's split out here
+// rather than referenced directly in reload.go, which would fail to
+// compile on Windows.
+func reloadTriggerSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR2}
+}
+
+func isReloadTrigger(sig os.Signal) bool {
+	return sig == syscall.SIGUSR2
+}
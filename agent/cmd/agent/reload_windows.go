@@ -0,0 +1,17 @@
+//go:build windows
+
+package main
+
+import "os"
+
+// reloadTriggerSignals is empty on Windows: SIGUSR2 doesn't exist there, so
+// a fork-and-drain reload can still be triggered via SIGHUP (handled
+// directly in watchSignals) but not via the extra signal unix platforms
+// get.
+func reloadTriggerSignals() []os.Signal {
+	return nil
+}
+
+func isReloadTrigger(sig os.Signal) bool {
+	return false
+}
@@ -0,0 +1,27 @@
+// Package config holds the agent's runtime configuration.
+package config
+
+// Config groups the agent's runtime settings. main does not build a Config
+// value: it fills a TLSConfig and a RetryPolicy from CLI flags directly.
+type Config struct {
+	ServerURL string
+	Token     string
+	TLS       *TLSConfig
+	Retry     RetryPolicy
+}
+
+// TLSConfig is the agent's WebSocket trust policy: which server origins it
+// will dial, and optionally a pinned server certificate fingerprint and/or
+// a private CA bundle to verify against instead of the system roots.
+type TLSConfig struct {
+	// AllowedOrigins lists the hosts (host[:port], as seen in the server
+	// URL) this agent is willing to dial. Empty means no origin
+	// restriction.
+	AllowedOrigins []string
+	// PinnedSHA256 is the expected hex-encoded SHA-256 fingerprint of the
+	// server's leaf certificate. Empty disables pinning.
+	PinnedSHA256 string
+	// CABundlePath, if set, replaces the system root pool for chain
+	// verification.
+	CABundlePath string
+}
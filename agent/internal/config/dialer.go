@@ -0,0 +1,112 @@
+package config
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/gorilla/websocket"
+)
+
+// TrustError indicates a connection was refused by the agent's own TLS/
+// origin trust policy, rather than failing at the transport layer. Callers
+// (e.g. the reconnect loop) should treat it as a permanent failure rather
+// than retrying with backoff.
+type TrustError struct {
+	Reason string
+}
+
+func (e *TrustError) Error() string {
+	return "trust policy violation: " + e.Reason
+}
+
+// AuthError indicates the server permanently refused this agent's
+// credentials (an HTTP 401/403 on the WebSocket handshake, or an
+// agent.identify rejection for an invalid or revoked token), rather than a
+// transient connectivity failure. Callers (e.g. the reconnect loop) should
+// treat it as a permanent failure and stop retrying, since backing off and
+// redialing with the same token will never succeed.
+type AuthError struct {
+	Reason string
+}
+
+func (e *AuthError) Error() string {
+	return "authentication rejected: " + e.Reason
+}
+
+// BuildDialer constructs a *websocket.Dialer (and any required request
+// headers) for dialing serverURL under this TLSConfig's policy. It refuses
+// to dial entirely, returning a *TrustError, if the URL's origin isn't
+// allowed or if a pin or a CA bundle is configured against a URL whose
+// scheme isn't wss://.
+func (t *TLSConfig) BuildDialer(serverURL string) (*websocket.Dialer, http.Header, error) {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	header := http.Header{}
+	if t != nil && len(t.AllowedOrigins) > 0 {
+		if !t.originAllowed(u.Host) {
+			return nil, nil, &TrustError{Reason: fmt.Sprintf("origin %q is not in the allowed-origins list", u.Host)}
+		}
+		header.Set("Origin", u.Scheme+"://"+u.Host)
+	}
+
+	dialer := *websocket.DefaultDialer
+
+	if t == nil || (t.PinnedSHA256 == "" && t.CABundlePath == "") {
+		return &dialer, header, nil
+	}
+
+	if u.Scheme != "wss" {
+		return nil, nil, &TrustError{Reason: "refusing to dial ws:// with a TLS pinning policy configured"}
+	}
+
+	tlsConf := &tls.Config{}
+	if t.CABundlePath != "" {
+		pem, err := os.ReadFile(t.CABundlePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("reading CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, nil, fmt.Errorf("no usable certificates in CA bundle %s", t.CABundlePath)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if t.PinnedSHA256 != "" {
+		tlsConf.VerifyPeerCertificate = t.verifyPin
+	}
+	dialer.TLSClientConfig = tlsConf
+
+	return &dialer, header, nil
+}
+
+func (t *TLSConfig) originAllowed(host string) bool {
+	for _, o := range t.AllowedOrigins {
+		if o == host {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyPin is installed as tls.Config.VerifyPeerCertificate. It runs after
+// normal chain verification succeeds and additionally requires the leaf
+// certificate's SHA-256 fingerprint to match the pinned value.
+func (t *TLSConfig) verifyPin(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return &TrustError{Reason: "server presented no certificate"}
+	}
+	sum := sha256.Sum256(rawCerts[0])
+	got := fmt.Sprintf("%x", sum)
+	if got != t.PinnedSHA256 {
+		return &TrustError{Reason: fmt.Sprintf("leaf certificate fingerprint %s does not match pinned %s", got, t.PinnedSHA256)}
+	}
+	return nil
+}
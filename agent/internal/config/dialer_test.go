@@ -0,0 +1,77 @@
+package config
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestBuildDialerRejectsDisallowedOrigin(t *testing.T) {
+	tlsConf := &TLSConfig{AllowedOrigins: []string{"allowed.example.com"}}
+	_, _, err := tlsConf.BuildDialer("wss://evil.example.com/ws")
+
+	var trustErr *TrustError
+	if !errors.As(err, &trustErr) {
+		t.Fatalf("expected a *TrustError, got %v", err)
+	}
+}
+
+func TestBuildDialerAllowsAllowlistedOrigin(t *testing.T) {
+	tlsConf := &TLSConfig{AllowedOrigins: []string{"allowed.example.com"}}
+	dialer, header, err := tlsConf.BuildDialer("wss://allowed.example.com/ws")
+	if err != nil {
+		t.Fatalf("BuildDialer: %v", err)
+	}
+	if dialer == nil {
+		t.Fatal("expected a non-nil dialer")
+	}
+	if got := header.Get("Origin"); got != "wss://allowed.example.com" {
+		t.Fatalf("Origin header = %q, want wss://allowed.example.com", got)
+	}
+}
+
+func TestBuildDialerRejectsPlaintextWithPinning(t *testing.T) {
+	tlsConf := &TLSConfig{PinnedSHA256: "deadbeef"}
+	_, _, err := tlsConf.BuildDialer("ws://server.example.com/ws")
+
+	var trustErr *TrustError
+	if !errors.As(err, &trustErr) {
+		t.Fatalf("expected a *TrustError for ws:// with pinning configured, got %v", err)
+	}
+}
+
+func TestBuildDialerAllowsPlaintextWithoutPolicy(t *testing.T) {
+	tlsConf := &TLSConfig{}
+	if _, _, err := tlsConf.BuildDialer("ws://server.example.com/ws"); err != nil {
+		t.Fatalf("expected ws:// to be allowed with no pinning/CA policy, got %v", err)
+	}
+}
+
+func TestVerifyPinRejectsMismatchedFingerprint(t *testing.T) {
+	tlsConf := &TLSConfig{PinnedSHA256: "0000000000000000000000000000000000000000000000000000000000000000"}
+	err := tlsConf.verifyPin([][]byte{[]byte("not the real cert bytes")}, nil)
+
+	var trustErr *TrustError
+	if !errors.As(err, &trustErr) {
+		t.Fatalf("expected a *TrustError for a fingerprint mismatch, got %v", err)
+	}
+}
+
+func TestVerifyPinAcceptsMatchingFingerprint(t *testing.T) {
+	leaf := []byte("pretend this is a DER-encoded certificate")
+	sum := sha256.Sum256(leaf)
+	tlsConf := &TLSConfig{PinnedSHA256: fmt.Sprintf("%x", sum)}
+
+	if err := tlsConf.verifyPin([][]byte{leaf}, nil); err != nil {
+		t.Fatalf("expected a matching fingerprint to be accepted, got %v", err)
+	}
+}
+
+func TestVerifyPinRejectsEmptyChain(t *testing.T) {
+	tlsConf := &TLSConfig{PinnedSHA256: "deadbeef"}
+	var trustErr *TrustError
+	if err := tlsConf.verifyPin(nil, nil); !errors.As(err, &trustErr) {
+		t.Fatalf("expected a *TrustError when the server presents no certificate, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy governs the agent's reconnect loop: how long to wait before
+// each successive dial attempt after the server connection is lost.
+type RetryPolicy struct {
+	// BaseDelay is the wait before the first retry.
+	BaseDelay time.Duration
+	// MaxDelay caps the exponential delay, however many attempts have failed
+	// in a row. Jitter is applied after the cap, so a single wait can exceed
+	// MaxDelay by up to MaxDelay*Jitter.
+	MaxDelay time.Duration
+	// Multiplier is applied to the delay after each failed attempt
+	// (BaseDelay, BaseDelay*Multiplier, BaseDelay*Multiplier^2, ...).
+	Multiplier float64
+	// Jitter is the fraction of the exponential delay to randomize, in
+	// [0, 1]. A delay of d becomes a uniformly random value in
+	// [d*(1-Jitter), d*(1+Jitter)], so a fleet of agents that lost their
+	// connection at the same moment doesn't all redial on the same cadence.
+	Jitter float64
+}
+
+// DefaultRetryPolicy returns the agent's out-of-the-box backoff: 1s, 2s,
+// 4s, ... capped at 5m, with +/-20% jitter.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		BaseDelay:  time.Second,
+		MaxDelay:   5 * time.Minute,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// NextDelay returns how long to wait before retry number attempt (0-based):
+// the uncapped exponential delay is computed, capped at MaxDelay, and then
+// randomized by +/-Jitter.
+func (p RetryPolicy) NextDelay(attempt int) time.Duration {
+	delay := p.cappedDelay(attempt)
+	if delay <= 0 {
+		return 0
+	}
+	if p.Jitter <= 0 {
+		return delay
+	}
+	spread := float64(delay) * p.Jitter
+	jittered := float64(delay) + spread*(2*rand.Float64()-1)
+	if jittered < 0 {
+		jittered = 0
+	}
+	return time.Duration(jittered)
+}
+
+func (p RetryPolicy) cappedDelay(attempt int) time.Duration {
+	delay := float64(p.BaseDelay)
+	for i := 0; i < attempt; i++ {
+		delay *= p.Multiplier
+		if delay >= float64(p.MaxDelay) {
+			return p.MaxDelay
+		}
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,60 @@
+package config
+
+import "testing"
+
+func TestRetryPolicyNextDelayNoJitterIsExact(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 1000, MaxDelay: 1000000, Multiplier: 2, Jitter: 0}
+
+	cases := map[int]int64{
+		0: 1000,
+		1: 2000,
+		2: 4000,
+	}
+	for attempt, want := range cases {
+		if got := p.NextDelay(attempt); int64(got) != want {
+			t.Errorf("NextDelay(%d) = %d, want %d", attempt, got, want)
+		}
+	}
+}
+
+func TestRetryPolicyNextDelayRespectsCap(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 1000, MaxDelay: 3000, Multiplier: 2, Jitter: 0}
+
+	if got := p.NextDelay(10); int64(got) != 3000 {
+		t.Fatalf("NextDelay(10) = %d, want capped at 3000", got)
+	}
+}
+
+func TestRetryPolicyNextDelayStaysWithinJitterBounds(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 1000, MaxDelay: 1000000, Multiplier: 2, Jitter: 0.2}
+
+	for attempt := 0; attempt < 5; attempt++ {
+		capped := int64(p.cappedDelay(attempt))
+		lo := capped - capped/5
+		hi := capped + capped/5
+		for i := 0; i < 50; i++ {
+			got := int64(p.NextDelay(attempt))
+			if got < lo || got > hi {
+				t.Fatalf("NextDelay(%d) = %d, want within [%d, %d]", attempt, got, lo, hi)
+			}
+		}
+	}
+}
+
+func TestRetryPolicyNextDelayNeverNegative(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 10, MaxDelay: 1000, Multiplier: 2, Jitter: 1}
+
+	for i := 0; i < 200; i++ {
+		if got := p.NextDelay(0); got < 0 {
+			t.Fatalf("NextDelay returned a negative duration: %d", got)
+		}
+	}
+}
+
+func TestRetryPolicyNextDelayZeroBaseDelayIsZero(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 0, MaxDelay: 1000, Multiplier: 2, Jitter: 0.2}
+
+	if got := p.NextDelay(3); got != 0 {
+		t.Fatalf("NextDelay with a zero BaseDelay = %d, want 0", got)
+	}
+}
@@ -0,0 +1,14 @@
+package diagnostic
+
+import "time"
+
+// HealthSource reports the orchestrator's current connection state, for the
+// /healthz and /readyz handlers. *orchestrator.Orchestrator implements this.
+type HealthSource interface {
+	// Connected reports whether the agent currently has a live connection
+	// to the server.
+	Connected() bool
+	// LastHeartbeat returns when the last heartbeat was successfully sent,
+	// or the zero time if none has been sent yet.
+	LastHeartbeat() time.Time
+}
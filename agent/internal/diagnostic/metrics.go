@@ -0,0 +1,164 @@
+// Package diagnostic serves the agent's own operational telemetry — health,
+// Prometheus metrics, and pprof profiles — over a local HTTP endpoint the
+// control-plane server never sees. This mirrors Teleport's
+// "ComponentDiagnostic" pattern: every long-running component gets the same
+// small set of operator-facing endpoints regardless of what it does.
+package diagnostic
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Counter is a monotonically increasing value, e.g. a count of commands
+// executed. The zero value is ready to use.
+type Counter struct {
+	v atomic.Int64
+}
+
+// Inc increments the counter by 1.
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Value returns the counter's current total.
+func (c *Counter) Value() int64 { return c.v.Load() }
+
+// defaultLatencyBuckets covers 5ms RPC round trips up through one-minute
+// command executions, in seconds, matching Prometheus's own convention for
+// *_seconds histograms. Anything over 60s is counted only in the +Inf bucket.
+var defaultLatencyBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60,
+}
+
+// Histogram buckets observed durations into defaultLatencyBuckets, cumulative
+// as Prometheus's exposition format expects.
+type Histogram struct {
+	mu      sync.Mutex
+	buckets []float64 // upper bounds, ascending
+	counts  []uint64  // counts[i] = observations <= buckets[i]
+	sum     float64
+	count   uint64
+}
+
+// NewHistogram returns a Histogram using the default latency buckets.
+func NewHistogram() *Histogram {
+	return &Histogram{
+		buckets: defaultLatencyBuckets,
+		counts:  make([]uint64, len(defaultLatencyBuckets)),
+	}
+}
+
+// Observe records a single duration, in seconds.
+func (h *Histogram) Observe(seconds float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sum += seconds
+	h.count++
+	i := sort.SearchFloat64s(h.buckets, seconds)
+	for ; i < len(h.counts); i++ {
+		h.counts[i]++
+	}
+}
+
+// snapshot returns a consistent copy of the histogram's state for rendering.
+func (h *Histogram) snapshot() (buckets []float64, counts []uint64, sum float64, count uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	counts = append([]uint64(nil), h.counts...)
+	return h.buckets, counts, h.sum, h.count
+}
+
+// Metrics holds every counter and histogram the diagnostic endpoint exposes.
+// It's created once in main and shared with the orchestrator and reconnect
+// loop, which record against it as they work.
+type Metrics struct {
+	CommandsExecuted  Counter
+	CommandFailures   Counter
+	MCPCalls          Counter
+	ReconnectAttempts Counter
+
+	CommandDuration *Histogram
+	WSRoundTrip     *Histogram
+}
+
+// NewMetrics returns an empty Metrics ready to be recorded against.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		CommandDuration: NewHistogram(),
+		WSRoundTrip:     NewHistogram(),
+	}
+}
+
+// WritePrometheus renders every metric in the Prometheus text exposition
+// format (https://prometheus.io/docs/instrumenting/exposition_formats/).
+func (m *Metrics) WritePrometheus(w io.Writer) error {
+	counters := []struct {
+		name string
+		help string
+		c    *Counter
+	}{
+		{"fabricore_commands_executed_total", "Total number of tool commands executed.", &m.CommandsExecuted},
+		{"fabricore_command_failures_total", "Total number of tool commands that returned an error.", &m.CommandFailures},
+		{"fabricore_mcp_calls_total", "Total number of MCP proxy requests handled.", &m.MCPCalls},
+		{"fabricore_reconnect_attempts_total", "Total number of reconnect attempts made to the server.", &m.ReconnectAttempts},
+	}
+
+	for _, entry := range counters {
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n%s %d\n",
+			entry.name, entry.help, entry.name, entry.name, entry.c.Value()); err != nil {
+			return err
+		}
+	}
+
+	histograms := []struct {
+		name string
+		help string
+		h    *Histogram
+	}{
+		{"fabricore_command_duration_seconds", "Duration of tool command executions, in seconds.", m.CommandDuration},
+		{"fabricore_ws_round_trip_seconds", "WebSocket ping/pong round-trip latency to the server, in seconds.", m.WSRoundTrip},
+	}
+
+	for _, entry := range histograms {
+		if err := writeHistogram(w, entry.name, entry.help, entry.h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeHistogram(w io.Writer, name, help string, h *Histogram) error {
+	buckets, counts, sum, count := h.snapshot()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name); err != nil {
+		return err
+	}
+	for i, upper := range buckets {
+		le := "+Inf"
+		if !math.IsInf(upper, 1) {
+			le = fmt.Sprintf("%g", upper)
+		}
+		if _, err := fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", name, le, counts[i]); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, count); err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "%s_sum %g\n%s_count %d\n", name, sum, name, count); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Time is a small helper for the common "observe how long this call took"
+// pattern: defer diagnostic.Time(h)() at the top of the timed section.
+func Time(h *Histogram) func() {
+	start := time.Now()
+	return func() { h.Observe(time.Since(start).Seconds()) }
+}
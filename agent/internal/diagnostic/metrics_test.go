@@ -0,0 +1,63 @@
+package diagnostic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCounterIncAndValue(t *testing.T) {
+	var c Counter
+	c.Inc()
+	c.Inc()
+	c.Inc()
+	if got := c.Value(); got != 3 {
+		t.Fatalf("Value() = %d, want 3", got)
+	}
+}
+
+func TestHistogramObserveBucketsCumulatively(t *testing.T) {
+	h := NewHistogram()
+	h.Observe(0.02)
+	h.Observe(2)
+
+	buckets, counts, sum, count := h.snapshot()
+	if count != 2 {
+		t.Fatalf("count = %d, want 2", count)
+	}
+	if sum != 2.02 {
+		t.Fatalf("sum = %g, want 2.02", sum)
+	}
+
+	for i, upper := range buckets {
+		if upper >= 2 && counts[i] != 2 {
+			t.Errorf("bucket le=%g = %d, want 2 (cumulative over both observations)", upper, counts[i])
+		}
+		if upper < 0.02 && counts[i] != 0 {
+			t.Errorf("bucket le=%g = %d, want 0 (below both observations)", upper, counts[i])
+		}
+	}
+}
+
+func TestMetricsWritePrometheusIncludesAllSeries(t *testing.T) {
+	m := NewMetrics()
+	m.CommandsExecuted.Inc()
+	m.CommandDuration.Observe(0.1)
+
+	var buf strings.Builder
+	if err := m.WritePrometheus(&buf); err != nil {
+		t.Fatalf("WritePrometheus: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"fabricore_commands_executed_total 1",
+		"fabricore_command_duration_seconds_bucket",
+		"fabricore_command_duration_seconds_sum",
+		"fabricore_command_duration_seconds_count 1",
+		"fabricore_ws_round_trip_seconds",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\nfull output:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package diagnostic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"time"
+
+	"github.com/fabricore/agent/internal/version"
+)
+
+// staleHeartbeat is how long since the last successful heartbeat before
+// /healthz reports the agent unhealthy even if the socket looks connected.
+const staleHeartbeat = 2 * time.Minute
+
+// Server is the agent's embedded diagnostic HTTP endpoint: health, metrics,
+// pprof, and version, all served on a separate listener from the
+// control-plane WebSocket connection so operators can reach it even if the
+// agent can't reach the server.
+type Server struct {
+	addr   string
+	health HealthSource
+	metric *Metrics
+	log    *slog.Logger
+	srv    *http.Server
+}
+
+// NewServer builds a diagnostic server that will listen on addr once
+// started. It serves nothing until Start is called.
+func NewServer(addr string, health HealthSource, metrics *Metrics, logger *slog.Logger) *Server {
+	s := &Server{addr: addr, health: health, metric: metrics, log: logger}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.HandleFunc("/readyz", s.handleReadyz)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/version", s.handleVersion)
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	s.srv = &http.Server{Addr: addr, Handler: mux}
+	return s
+}
+
+// Start runs the diagnostic server until ctx is cancelled. It's meant to be
+// run in its own goroutine; a bind failure is logged rather than returned,
+// since the diagnostic endpoint is a convenience for operators, not
+// something the agent's core job depends on.
+func (s *Server) Start(ctx context.Context) {
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.srv.Shutdown(shutdownCtx)
+	}()
+
+	s.log.Info("diagnostic endpoint listening", "addr", s.addr)
+	if err := s.srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.Error("diagnostic endpoint failed", "addr", s.addr, "error", err)
+	}
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	connected := s.health.Connected()
+	lastHeartbeat := s.health.LastHeartbeat()
+
+	age := -1.0
+	stale := true
+	if !lastHeartbeat.IsZero() {
+		age = time.Since(lastHeartbeat).Seconds()
+		stale = time.Since(lastHeartbeat) > staleHeartbeat
+	}
+
+	healthy := connected && !stale
+	status := http.StatusOK
+	if !healthy {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"connected": connected,
+		"last_heartbeat_age_s": age,
+		"healthy": healthy,
+	})
+}
+
+// handleReadyz answers whether the agent is ready to be sent work. It checks
+// only that the agent is connected; unlike /healthz it does not look at the
+// age of the last heartbeat.
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	if !s.health.Connected() {
+		http.Error(w, "not connected", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte("ready\n"))
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	if err := s.metric.WritePrometheus(w); err != nil {
+		s.log.Warn("failed to write metrics response", "error", err)
+	}
+}
+
+func (s *Server) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"version": version.Version})
+}
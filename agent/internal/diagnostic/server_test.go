@@ -0,0 +1,82 @@
+package diagnostic
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeHealthSource struct {
+	connected     bool
+	lastHeartbeat time.Time
+}
+
+func (f fakeHealthSource) Connected() bool          { return f.connected }
+func (f fakeHealthSource) LastHeartbeat() time.Time { return f.lastHeartbeat }
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestHandleHealthzHealthyWhenConnectedAndFresh(t *testing.T) {
+	s := NewServer(":0", fakeHealthSource{connected: true, lastHeartbeat: time.Now()}, NewMetrics(), discardLogger())
+
+	rec := httptest.NewRecorder()
+	s.handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestHandleHealthzUnhealthyWhenDisconnected(t *testing.T) {
+	s := NewServer(":0", fakeHealthSource{connected: false}, NewMetrics(), discardLogger())
+
+	rec := httptest.NewRecorder()
+	s.handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+}
+
+func TestHandleHealthzUnhealthyWhenHeartbeatStale(t *testing.T) {
+	s := NewServer(":0", fakeHealthSource{connected: true, lastHeartbeat: time.Now().Add(-10 * time.Minute)}, NewMetrics(), discardLogger())
+
+	rec := httptest.NewRecorder()
+	s.handleHealthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 for a stale heartbeat", rec.Code)
+	}
+}
+
+func TestHandleReadyzRequiresConnection(t *testing.T) {
+	s := NewServer(":0", fakeHealthSource{connected: false}, NewMetrics(), discardLogger())
+
+	rec := httptest.NewRecorder()
+	s.handleReadyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503 when not connected", rec.Code)
+	}
+}
+
+func TestHandleMetricsServesPrometheusFormat(t *testing.T) {
+	metrics := NewMetrics()
+	metrics.MCPCalls.Inc()
+	s := NewServer(":0", fakeHealthSource{connected: true}, metrics, discardLogger())
+
+	rec := httptest.NewRecorder()
+	s.handleMetrics(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+}
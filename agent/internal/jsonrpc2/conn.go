@@ -0,0 +1,347 @@
+// Package jsonrpc2 implements a bidirectional JSON-RPC 2.0 connection, in
+// the spirit of the one used by the Go tools LSP libraries: either side of
+// a Conn may call the other, and every outbound call can be cancelled.
+package jsonrpc2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Request is an inbound JSON-RPC request or notification delivered to a
+// Handler.
+type Request struct {
+	Method string
+	Params json.RawMessage
+	id     *ID
+}
+
+// ID returns the request's id and true, or the zero ID and false if this is
+// a notification.
+func (r *Request) ID() (ID, bool) {
+	if r.id == nil {
+		return ID{}, false
+	}
+	return *r.id, true
+}
+
+// IsNotification reports whether the request carries no id, and therefore
+// expects no response.
+func (r *Request) IsNotification() bool {
+	return r.id == nil
+}
+
+// Handler processes a single inbound request. ctx is cancelled if the peer
+// sends a matching "$/cancelRequest" before Handle returns. The returned
+// value is marshalled into the response's "result"; returning an *Error
+// sets the response's "error" verbatim, any other error is wrapped as
+// CodeInternalError.
+type Handler interface {
+	Handle(ctx context.Context, conn *Conn, r *Request) (interface{}, error)
+}
+
+// HandlerFunc adapts a plain function to Handler.
+type HandlerFunc func(ctx context.Context, conn *Conn, r *Request) (interface{}, error)
+
+func (f HandlerFunc) Handle(ctx context.Context, conn *Conn, r *Request) (interface{}, error) {
+	return f(ctx, conn, r)
+}
+
+// Conn is a single bidirectional JSON-RPC 2.0 connection over a Stream.
+type Conn struct {
+	stream  Stream
+	handler Handler
+
+	seq uint64 // atomic; allocates outbound request ids
+
+	mu       sync.Mutex
+	pending  map[ID]chan *wireResponse
+	handling map[ID]context.CancelFunc
+	closed   bool
+}
+
+// NewConn creates a Conn around stream. handler may be nil if this side
+// never receives requests (only calls out).
+func NewConn(stream Stream, handler Handler) *Conn {
+	return &Conn{
+		stream:   stream,
+		handler:  handler,
+		pending:  make(map[ID]chan *wireResponse),
+		handling: make(map[ID]context.CancelFunc),
+	}
+}
+
+// Run reads and dispatches messages until the stream's Read returns an
+// error, and returns that error. Close makes the next Read fail, so Run
+// returns a non-nil error after a Close as well. ctx is passed to Read and
+// to every inbound handler; whether cancelling it ends a blocked Read is up
+// to the Stream.
+func (c *Conn) Run(ctx context.Context) error {
+	for {
+		msg, err := c.stream.Read(ctx)
+		if err != nil {
+			c.shutdown()
+			return err
+		}
+		c.dispatch(ctx, msg)
+	}
+}
+
+func (c *Conn) dispatch(ctx context.Context, msg []byte) {
+	// Peek at "method" to tell requests/notifications from responses
+	// without committing to either struct shape up front.
+	var peek struct {
+		Method *string `json:"method"`
+		ID     *ID     `json:"id"`
+	}
+	if err := json.Unmarshal(msg, &peek); err != nil {
+		log.Printf("[WARN] jsonrpc2: dropping unparseable message: %v", err)
+		return
+	}
+
+	if peek.Method == nil {
+		// A response with no method. Only deliver it if we actually have a
+		// pending caller waiting on this id.
+		var resp wireResponse
+		if err := json.Unmarshal(msg, &resp); err != nil {
+			log.Printf("[WARN] jsonrpc2: dropping unparseable response: %v", err)
+			return
+		}
+		c.deliverResponse(&resp)
+		return
+	}
+
+	if *peek.Method == cancelMethod {
+		c.handleCancel(msg)
+		return
+	}
+
+	var req wireRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		log.Printf("[WARN] jsonrpc2: dropping unparseable request: %v", err)
+		return
+	}
+	go c.handleRequest(ctx, &req)
+}
+
+func (c *Conn) deliverResponse(resp *wireResponse) {
+	if resp.ID == nil {
+		return
+	}
+	c.mu.Lock()
+	ch, ok := c.pending[*resp.ID]
+	if ok {
+		delete(c.pending, *resp.ID)
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		log.Printf("[WARN] jsonrpc2: response for unknown id %s, dropping", resp.ID)
+		return
+	}
+	ch <- resp
+}
+
+func (c *Conn) handleCancel(msg []byte) {
+	var req wireRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return
+	}
+	var params cancelParams
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return
+	}
+	c.mu.Lock()
+	cancel, ok := c.handling[params.ID]
+	c.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+func (c *Conn) handleRequest(ctx context.Context, req *wireRequest) {
+	if c.handler == nil {
+		if req.ID != nil {
+			c.writeResponse(ctx, &wireResponse{
+				JSONRPC: "2.0",
+				ID:      req.ID,
+				Error:   &Error{Code: CodeMethodNotFound, Message: "method not found"},
+			})
+		}
+		return
+	}
+
+	reqCtx := ctx
+	var cancel context.CancelFunc
+	if req.ID != nil {
+		reqCtx, cancel = context.WithCancel(ctx)
+		c.mu.Lock()
+		c.handling[*req.ID] = cancel
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.handling, *req.ID)
+			c.mu.Unlock()
+			cancel()
+		}()
+	}
+
+	result, err := c.handler.Handle(reqCtx, c, &Request{Method: req.Method, Params: req.Params, id: req.ID})
+	if req.ID == nil {
+		return
+	}
+
+	resp := &wireResponse{JSONRPC: "2.0", ID: req.ID}
+	if err != nil {
+		if rpcErr, ok := err.(*Error); ok {
+			resp.Error = rpcErr
+		} else {
+			resp.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+		}
+	} else {
+		raw, merr := json.Marshal(result)
+		if merr != nil {
+			resp.Error = &Error{Code: CodeInternalError, Message: merr.Error()}
+		} else {
+			resp.Result = raw
+		}
+	}
+	c.writeResponse(ctx, resp)
+}
+
+func (c *Conn) writeResponse(ctx context.Context, resp *wireResponse) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("[ERROR] jsonrpc2: failed to marshal response: %v", err)
+		return
+	}
+	if err := c.stream.Write(ctx, data); err != nil {
+		log.Printf("[WARN] jsonrpc2: failed to write response: %v", err)
+	}
+}
+
+// Call sends a request and blocks until the matching response arrives or
+// ctx is cancelled, in which case a "$/cancelRequest" notification is sent
+// and the call returns ctx.Err(). If result is non-nil, the response's
+// "result" is unmarshalled into it.
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	id := NewNumberID(int64(atomic.AddUint64(&c.seq, 1)))
+
+	raw, err := marshalParams(params)
+	if err != nil {
+		return err
+	}
+
+	ch := make(chan *wireResponse, 1)
+	c.mu.Lock()
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	req := &wireRequest{JSONRPC: "2.0", Method: method, Params: raw, ID: &id}
+	data, err := json.Marshal(req)
+	if err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return err
+	}
+	if err := c.stream.Write(ctx, data); err != nil {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		return err
+	}
+
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			return json.Unmarshal(resp.Result, result)
+		}
+		return nil
+	case <-ctx.Done():
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+		c.cancelOutbound(id)
+		return ctx.Err()
+	}
+}
+
+func (c *Conn) cancelOutbound(id ID) {
+	raw, err := marshalParams(cancelParams{ID: id})
+	if err != nil {
+		return
+	}
+	req := &wireRequest{JSONRPC: "2.0", Method: cancelMethod, Params: raw}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return
+	}
+	// Best effort: the connection may already be going away.
+	_ = c.stream.Write(context.Background(), data)
+}
+
+// Notify sends a request with no id, expecting no response.
+func (c *Conn) Notify(ctx context.Context, method string, params interface{}) error {
+	raw, err := marshalParams(params)
+	if err != nil {
+		return err
+	}
+	req := &wireRequest{JSONRPC: "2.0", Method: method, Params: raw}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	return c.stream.Write(ctx, data)
+}
+
+// Close shuts down the underlying stream and releases all pending callers.
+func (c *Conn) Close() error {
+	err := c.stream.Close()
+	c.shutdown()
+	return err
+}
+
+func (c *Conn) shutdown() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return
+	}
+	c.closed = true
+	for id, ch := range c.pending {
+		id := id
+		// ch is buffered (size 1), so this never blocks: deliver a
+		// synthetic error response rather than just closing the channel,
+		// since Call's receive can't otherwise tell a closed channel from
+		// one that received a real response and would dereference a nil
+		// *wireResponse.
+		ch <- &wireResponse{JSONRPC: "2.0", ID: &id, Error: &Error{Code: CodeInternalError, Message: "connection closed"}}
+		delete(c.pending, id)
+	}
+	for id, cancel := range c.handling {
+		cancel()
+		delete(c.handling, id)
+	}
+}
+
+func marshalParams(params interface{}) (json.RawMessage, error) {
+	if params == nil {
+		return nil, nil
+	}
+	if raw, ok := params.(json.RawMessage); ok {
+		return raw, nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("jsonrpc2: marshal params: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,94 @@
+package jsonrpc2
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+// pipeStream is a minimal Stream with no real peer: writes are discarded and
+// Read blocks until the stream is closed, just enough to drive Conn through
+// Call/Close without a second party.
+type pipeStream struct {
+	mu     sync.Mutex
+	closed bool
+	ch     chan []byte
+}
+
+func newPipeStream() *pipeStream {
+	return &pipeStream{ch: make(chan []byte)}
+}
+
+func (s *pipeStream) Read(ctx context.Context) ([]byte, error) {
+	select {
+	case msg, ok := <-s.ch:
+		if !ok {
+			return nil, io.EOF
+		}
+		return msg, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *pipeStream) Write(ctx context.Context, msg []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return io.ErrClosedPipe
+	}
+	return nil
+}
+
+func (s *pipeStream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.ch)
+	}
+	return nil
+}
+
+// TestCallReturnsErrorOnShutdown reproduces the panic this fixes: Close
+// (and therefore shutdown) used to close a pending call's channel with no
+// value, so Call's `resp := <-ch` received a nil *wireResponse and crashed
+// dereferencing resp.Error. It must now return a plain error instead.
+func TestCallReturnsErrorOnShutdown(t *testing.T) {
+	conn := NewConn(newPipeStream(), nil)
+
+	callErr := make(chan error, 1)
+	go func() {
+		callErr <- conn.Call(context.Background(), "some.method", nil, nil)
+	}()
+
+	// Give Call a moment to register itself in c.pending before closing.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	select {
+	case err := <-callErr:
+		if err == nil {
+			t.Fatal("expected an error from Call after the connection closed, got nil")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Call did not return after Close")
+	}
+}
+
+// TestShutdownIsIdempotent ensures a second Close on an already-closed Conn
+// returns without an error and doesn't panic.
+func TestShutdownIsIdempotent(t *testing.T) {
+	conn := NewConn(newPipeStream(), nil)
+	if err := conn.Close(); err != nil {
+		t.Fatalf("first Close: %v", err)
+	}
+	if err := conn.Close(); err != nil {
+		t.Fatalf("second Close: %v", err)
+	}
+}
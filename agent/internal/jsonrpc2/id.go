@@ -0,0 +1,56 @@
+package jsonrpc2
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// ID is a JSON-RPC 2.0 request identifier. It may hold either a string or a
+// number, matching the spec's "id" field, and is comparable so it can be
+// used directly as a map key.
+type ID struct {
+	isString bool
+	str      string
+	num      int64
+}
+
+// NewNumberID builds an ID from an integer, as used for agent-originated
+// outbound calls.
+func NewNumberID(n int64) ID {
+	return ID{num: n}
+}
+
+// NewStringID builds an ID from a string.
+func NewStringID(s string) ID {
+	return ID{isString: true, str: s}
+}
+
+// String renders the ID for logging.
+func (id ID) String() string {
+	if id.isString {
+		return id.str
+	}
+	return strconv.FormatInt(id.num, 10)
+}
+
+func (id ID) MarshalJSON() ([]byte, error) {
+	if id.isString {
+		return json.Marshal(id.str)
+	}
+	return json.Marshal(id.num)
+}
+
+func (id *ID) UnmarshalJSON(data []byte) error {
+	var num int64
+	if err := json.Unmarshal(data, &num); err == nil {
+		*id = ID{num: num}
+		return nil
+	}
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*id = ID{isString: true, str: str}
+		return nil
+	}
+	return fmt.Errorf("jsonrpc2: id is neither string nor number: %s", data)
+}
@@ -0,0 +1,93 @@
+package jsonrpc2
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// Stream is a transport-agnostic carrier of framed JSON-RPC messages. Conn
+// is agnostic to what's underneath: a WebSocket connection, an MCP server's
+// stdio pipes, or any other newline-delimited pipe.
+type Stream interface {
+	// Read blocks for the next message, or returns an error if the stream
+	// fails or is closed. The WebSocket and NDJSON streams in this package
+	// do not watch ctx: cancelling it does not interrupt a blocked Read.
+	Read(ctx context.Context) ([]byte, error)
+	// Write sends a single framed message.
+	Write(ctx context.Context, msg []byte) error
+	// Close releases the underlying transport.
+	Close() error
+}
+
+// wsStream adapts a *websocket.Conn to Stream.
+type wsStream struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+}
+
+// NewWebSocketStream wraps an established WebSocket connection as a Stream.
+func NewWebSocketStream(conn *websocket.Conn) Stream {
+	return &wsStream{conn: conn}
+}
+
+func (s *wsStream) Read(ctx context.Context) ([]byte, error) {
+	_, msg, err := s.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func (s *wsStream) Write(ctx context.Context, msg []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteMessage(websocket.TextMessage, msg)
+}
+
+func (s *wsStream) Close() error {
+	return s.conn.Close()
+}
+
+// ndjsonStream frames messages as newline-delimited JSON over an
+// io.ReadWriteCloser, the shape used by stdio-based MCP servers.
+type ndjsonStream struct {
+	rwc io.ReadWriteCloser
+	r   *bufio.Reader
+
+	writeMu sync.Mutex
+}
+
+// NewNDJSONStream wraps a pipe (e.g. a subprocess's stdin/stdout) as a
+// newline-delimited JSON Stream.
+func NewNDJSONStream(rwc io.ReadWriteCloser) Stream {
+	return &ndjsonStream{rwc: rwc, r: bufio.NewReader(rwc)}
+}
+
+func (s *ndjsonStream) Read(ctx context.Context) ([]byte, error) {
+	line, err := s.r.ReadBytes('\n')
+	if len(line) == 0 {
+		return nil, err
+	}
+	// Preserve a trailing read error (e.g. io.EOF on the final unterminated
+	// line) only once the line itself has been delivered.
+	return line, nil
+}
+
+func (s *ndjsonStream) Write(ctx context.Context, msg []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if _, err := s.rwc.Write(msg); err != nil {
+		return err
+	}
+	_, err := s.rwc.Write([]byte{'\n'})
+	return err
+}
+
+func (s *ndjsonStream) Close() error {
+	return s.rwc.Close()
+}
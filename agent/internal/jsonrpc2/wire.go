@@ -0,0 +1,54 @@
+package jsonrpc2
+
+import "encoding/json"
+
+// wireRequest is the on-the-wire shape of a JSON-RPC 2.0 request or
+// notification. ID is a pointer so notifications (no "id" field at all) are
+// distinguishable from requests with a zero-value id.
+type wireRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      *ID             `json:"id,omitempty"`
+}
+
+// wireResponse is the on-the-wire shape of a JSON-RPC 2.0 response.
+type wireResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+	ID      *ID             `json:"id,omitempty"`
+}
+
+// Error is a JSON-RPC 2.0 error object.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Standard JSON-RPC 2.0 error codes, plus the application-defined codes used
+// elsewhere in the agent (see internal/orchestrator).
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	CodeCancelled      = -32002
+	// CodeUnauthorized is returned by the server for agent.identify when the
+	// agent's token is invalid or has been revoked. The reconnect loop treats
+	// it as a permanent failure (see config.AuthError) rather than retrying.
+	CodeUnauthorized = -32003
+)
+
+// cancelParams is the payload of the "$/cancelRequest" notification.
+type cancelParams struct {
+	ID ID `json:"id"`
+}
+
+const cancelMethod = "$/cancelRequest"
@@ -0,0 +1,69 @@
+// Package logging builds the agent's structured loggers: one root logger
+// per process, with per-subsystem children (agent.sys, agent.mcp,
+// agent.orch, agent.sec, ...) that can be filtered or routed independently
+// by anything ingesting the JSON output.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Format selects the root logger's line encoding.
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+)
+
+// New builds the root logger, writing level-filtered records to w in the
+// requested format.
+func New(format Format, level slog.Level, w io.Writer) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		handler = slog.NewTextHandler(w, opts)
+	}
+	return slog.New(handler)
+}
+
+// Named derives a subsystem logger (e.g. "agent.sys", "agent.mcp") from the
+// root logger, tagging every record it emits with a "component" field.
+func Named(root *slog.Logger, component string) *slog.Logger {
+	return root.With(slog.String("component", component))
+}
+
+// ParseLevel maps a --log-level flag value to a slog.Level.
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (want debug, info, warn, or error)", s)
+	}
+}
+
+// ParseFormat maps a --log-format flag value to a Format.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "text":
+		return FormatText, nil
+	case "json":
+		return FormatJSON, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (want text or json)", s)
+	}
+}
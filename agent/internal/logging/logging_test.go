@@ -0,0 +1,106 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"info":    slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"error":   slog.LevelError,
+		"ERROR":   slog.LevelError,
+	}
+	for in, want := range cases {
+		got, err := ParseLevel(in)
+		if err != nil {
+			t.Errorf("ParseLevel(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestParseLevelRejectsUnknown(t *testing.T) {
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Fatal("expected an error for an unknown log level")
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{
+		"":     FormatText,
+		"text": FormatText,
+		"json": FormatJSON,
+		"JSON": FormatJSON,
+	}
+	for in, want := range cases {
+		got, err := ParseFormat(in)
+		if err != nil {
+			t.Errorf("ParseFormat(%q): %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ParseFormat(%q) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestParseFormatRejectsUnknown(t *testing.T) {
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Fatal("expected an error for an unknown log format")
+	}
+}
+
+func TestNewJSONHandlerEmitsParsableJSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(FormatJSON, slog.LevelInfo, &buf)
+	logger.Info("hello", "key", "value")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output isn't valid JSON: %v\n%s", err, buf.String())
+	}
+	if record["msg"] != "hello" || record["key"] != "value" {
+		t.Fatalf("unexpected record: %v", record)
+	}
+}
+
+func TestNewTextHandlerFiltersBelowLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(FormatText, slog.LevelWarn, &buf)
+	logger.Info("should be filtered out")
+	logger.Warn("should appear")
+
+	out := buf.String()
+	if strings.Contains(out, "should be filtered out") {
+		t.Fatalf("expected info-level record to be filtered at warn level, got: %s", out)
+	}
+	if !strings.Contains(out, "should appear") {
+		t.Fatalf("expected warn-level record to appear, got: %s", out)
+	}
+}
+
+func TestNamedTagsComponent(t *testing.T) {
+	var buf bytes.Buffer
+	root := New(FormatJSON, slog.LevelInfo, &buf)
+	sub := Named(root, "agent.sys")
+	sub.Info("tagged")
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("output isn't valid JSON: %v", err)
+	}
+	if record["component"] != "agent.sys" {
+		t.Fatalf("component = %v, want agent.sys", record["component"])
+	}
+}
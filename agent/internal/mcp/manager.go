@@ -1,8 +1,13 @@
 package mcp
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"log/slog"
 	"os"
+	"sync"
+	"time"
 
 	"github.com/fabricore/agent/internal/types"
 )
@@ -13,39 +18,109 @@ type Manager interface {
 }
 
 type RealManager struct {
-	servers map[string]types.MCPServerInfo
+	log *slog.Logger
+
+	mu         sync.Mutex
+	servers    map[string]types.MCPServerInfo
+	transports map[string]Transport
 }
 
-func NewManager() *RealManager {
+func NewManager(logger *slog.Logger) *RealManager {
 	return &RealManager{
-		servers: make(map[string]types.MCPServerInfo),
+		log:        logger,
+		servers:    make(map[string]types.MCPServerInfo),
+		transports: make(map[string]Transport),
 	}
 }
 
+// ScanLocalServers reads mcp_config.json and probes each declared server
+// with an "initialize" handshake, recording whether it came up cleanly.
 func (m *RealManager) ScanLocalServers() ([]types.MCPServerInfo, error) {
-	// Try to read mcp_config.json
 	data, err := os.ReadFile("mcp_config.json")
-	if err == nil {
-		var servers []types.MCPServerInfo
-		if err := json.Unmarshal(data, &servers); err == nil {
-			for _, s := range servers {
-				m.servers[s.Name] = s
-			}
-			return servers, nil
+	if err != nil {
+		return []types.MCPServerInfo{}, nil
+	}
+
+	var servers []types.MCPServerInfo
+	if err := json.Unmarshal(data, &servers); err != nil {
+		return []types.MCPServerInfo{}, nil
+	}
+
+	for i, s := range servers {
+		if err := m.probe(s); err != nil {
+			m.log.Warn("mcp server failed initialize probe", "server", s.Name, "error", err)
+			servers[i].Status = "error"
+		} else {
+			m.log.Debug("mcp server ready", "server", s.Name)
+			servers[i].Status = "ready"
 		}
+		m.mu.Lock()
+		m.servers[s.Name] = servers[i]
+		m.mu.Unlock()
+	}
+
+	return servers, nil
+}
+
+func (m *RealManager) probe(info types.MCPServerInfo) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := m.send(ctx, info, types.JSONRPCRequest{
+		JSONRPC: types.JSONRPCVersion,
+		Method:  "initialize",
+		ID:      "probe",
+	})
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("initialize failed: %s", resp.Error.Message)
 	}
-	return []types.MCPServerInfo{}, nil
+	return nil
 }
 
+// ProxyRequest forwards request to the named MCP server over its declared
+// transport (stdio or SSE/HTTP) and returns the server's response.
 func (m *RealManager) ProxyRequest(serverName string, request types.JSONRPCRequest) (types.JSONRPCResponse, error) {
-	// TODO: Implement actual transport (Stdio/SSE)
-	// For now, return a placeholder error
-	return types.JSONRPCResponse{
-		JSONRPC: "2.0",
-		ID:      request.ID,
-		Error: &types.JSONRPCError{
-			Code:    -32603,
-			Message: "MCP Proxy not implemented yet",
-		},
-	}, nil
+	m.mu.Lock()
+	info, ok := m.servers[serverName]
+	m.mu.Unlock()
+	if !ok {
+		return types.JSONRPCResponse{}, fmt.Errorf("unknown mcp server: %s", serverName)
+	}
+
+	return m.send(context.Background(), info, request)
+}
+
+func (m *RealManager) send(ctx context.Context, info types.MCPServerInfo, request types.JSONRPCRequest) (types.JSONRPCResponse, error) {
+	t, err := m.transportFor(info)
+	if err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+	return t.Send(ctx, request)
+}
+
+func (m *RealManager) transportFor(info types.MCPServerInfo) (Transport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.transports[info.Name]; ok {
+		return t, nil
+	}
+	t, err := newTransport(info, m.log)
+	if err != nil {
+		return nil, err
+	}
+	m.transports[info.Name] = t
+	return t, nil
+}
+
+// Close tears down every live MCP transport (stdio children, SSE clients).
+func (m *RealManager) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, t := range m.transports {
+		t.Close()
+	}
 }
@@ -0,0 +1,282 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabricore/agent/internal/jsonrpc2"
+	"github.com/fabricore/agent/internal/types"
+)
+
+// Transport carries JSON-RPC requests to a single MCP server and returns its
+// response. Implementations are shared across ProxyRequest calls for the
+// same server so stdio children and SSE streams are reused rather than
+// re-established on every call.
+type Transport interface {
+	Send(ctx context.Context, req types.JSONRPCRequest) (types.JSONRPCResponse, error)
+	Close() error
+}
+
+const defaultCallTimeout = 30 * time.Second
+
+func newTransport(info types.MCPServerInfo, logger *slog.Logger) (Transport, error) {
+	if info.Transport == "stdio" {
+		if len(info.Command) == 0 {
+			return nil, fmt.Errorf("mcp server %q: stdio transport requires a command", info.Name)
+		}
+		return newStdioTransport(info, logger), nil
+	}
+	if info.URL == "" {
+		return nil, fmt.Errorf("mcp server %q: transport %q requires a url", info.Name, info.Transport)
+	}
+	return newSSETransport(info, logger), nil
+}
+
+// --- stdio transport --------------------------------------------------
+
+// stdioTransport spawns Command on first use and keeps the child alive
+// across calls, restarting it with backoff if it crashes.
+type stdioTransport struct {
+	info types.MCPServerInfo
+	log  *slog.Logger
+
+	mu      sync.Mutex
+	cmd     *exec.Cmd
+	conn    *jsonrpc2.Conn
+	backoff time.Duration
+}
+
+func newStdioTransport(info types.MCPServerInfo, logger *slog.Logger) *stdioTransport {
+	return &stdioTransport{info: info, log: logger}
+}
+
+func (t *stdioTransport) Send(ctx context.Context, req types.JSONRPCRequest) (types.JSONRPCResponse, error) {
+	conn, err := t.ensureStarted(ctx)
+	if err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, defaultCallTimeout)
+	defer cancel()
+
+	var result json.RawMessage
+	callErr := conn.Call(callCtx, req.Method, req.Params, &result)
+	if rpcErr, ok := callErr.(*jsonrpc2.Error); ok {
+		return types.JSONRPCResponse{
+			JSONRPC: types.JSONRPCVersion,
+			ID:      req.ID,
+			Error:   &types.JSONRPCError{Code: rpcErr.Code, Message: rpcErr.Message, Data: rpcErr.Data},
+		}, nil
+	}
+	if callErr != nil {
+		// Transport-level failure (process died, deadline, etc): drop the
+		// child so the next call respawns it.
+		t.restart()
+		return types.JSONRPCResponse{}, callErr
+	}
+
+	return types.JSONRPCResponse{JSONRPC: types.JSONRPCVersion, ID: req.ID, Result: result}, nil
+}
+
+func (t *stdioTransport) ensureStarted(ctx context.Context) (*jsonrpc2.Conn, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.conn != nil {
+		return t.conn, nil
+	}
+
+	cmd := exec.Command(t.info.Command[0], t.info.Command[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Stderr = nil
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("mcp server %q: failed to start: %w", t.info.Name, err)
+	}
+
+	pipe := &stdioPipe{stdin: stdin, stdout: stdout}
+	conn := jsonrpc2.NewConn(jsonrpc2.NewNDJSONStream(pipe), nil)
+
+	t.cmd = cmd
+	t.conn = conn
+
+	go func() {
+		err := conn.Run(context.Background())
+		t.log.Warn("mcp stdio connection ended", "server", t.info.Name, "error", err)
+		t.mu.Lock()
+		if t.conn == conn {
+			t.conn = nil
+			t.cmd = nil
+		}
+		t.mu.Unlock()
+	}()
+
+	t.backoff = 0
+	return conn, nil
+}
+
+// restart drops the current child/connection and sleeps for the backoff
+// before returning; the next Send respawns it. The backoff doubles from
+// 500ms on each restart, but ensureStarted resets it to 0 on every
+// successful spawn, so each restart after a successful spawn waits 500ms.
+func (t *stdioTransport) restart() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+	if t.cmd != nil && t.cmd.Process != nil {
+		t.cmd.Process.Kill()
+		t.cmd = nil
+	}
+	if t.backoff == 0 {
+		t.backoff = 500 * time.Millisecond
+	} else if t.backoff < 30*time.Second {
+		t.backoff *= 2
+	}
+	time.Sleep(t.backoff)
+}
+
+func (t *stdioTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn != nil {
+		t.conn.Close()
+		t.conn = nil
+	}
+	if t.cmd != nil && t.cmd.Process != nil {
+		t.cmd.Process.Kill()
+		t.cmd = nil
+	}
+	return nil
+}
+
+// stdioPipe adapts a child process's stdin/stdout pipes to io.ReadWriteCloser.
+type stdioPipe struct {
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+func (p *stdioPipe) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *stdioPipe) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+func (p *stdioPipe) Close() error {
+	_ = p.stdin.Close()
+	return p.stdout.Close()
+}
+
+// --- SSE transport ------------------------------------------------------
+
+// sseTransport POSTs each request to URL and reads the response back as a
+// text/event-stream, matching frames by id since the stream may interleave
+// unrelated server-initiated events.
+type sseTransport struct {
+	info   types.MCPServerInfo
+	log    *slog.Logger
+	client *http.Client
+}
+
+func newSSETransport(info types.MCPServerInfo, logger *slog.Logger) *sseTransport {
+	return &sseTransport{info: info, log: logger, client: &http.Client{}}
+}
+
+// Close is a no-op: sseTransport holds no long-lived connection between calls.
+func (t *sseTransport) Close() error {
+	return nil
+}
+
+func (t *sseTransport) Send(ctx context.Context, req types.JSONRPCRequest) (types.JSONRPCResponse, error) {
+	callCtx, cancel := context.WithTimeout(ctx, defaultCallTimeout)
+	defer cancel()
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(callCtx, http.MethodPost, t.info.URL, bytes.NewReader(body))
+	if err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := t.client.Do(httpReq)
+	if err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return types.JSONRPCResponse{}, fmt.Errorf("mcp server %q: unexpected status %d", t.info.Name, resp.StatusCode)
+	}
+
+	return readSSEResponse(callCtx, resp.Body, req.ID, t.log)
+}
+
+// readSSEResponse scans "event: message" / "data: ..." frames until one
+// decodes to a response whose id matches want, or the stream/context ends.
+func readSSEResponse(ctx context.Context, body io.Reader, want interface{}, logger *slog.Logger) (types.JSONRPCResponse, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var dataLines []string
+	flush := func() (types.JSONRPCResponse, bool, error) {
+		if len(dataLines) == 0 {
+			return types.JSONRPCResponse{}, false, nil
+		}
+		payload := strings.Join(dataLines, "\n")
+		dataLines = dataLines[:0]
+
+		var resp types.JSONRPCResponse
+		if err := json.Unmarshal([]byte(payload), &resp); err != nil {
+			return types.JSONRPCResponse{}, false, nil
+		}
+		if !types.IDsEqual(resp.ID, want) {
+			logger.Warn("mcp: dropping response for unexpected id", "got", resp.ID, "want", want)
+			return types.JSONRPCResponse{}, false, nil
+		}
+		return resp, true, nil
+	}
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return types.JSONRPCResponse{}, err
+		}
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if resp, ok, err := flush(); err != nil || ok {
+				return resp, err
+			}
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "event:"), strings.HasPrefix(line, ":"):
+			// ignored: event name / comment
+		}
+	}
+	if resp, ok, _ := flush(); ok {
+		return resp, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return types.JSONRPCResponse{}, err
+	}
+	return types.JSONRPCResponse{}, fmt.Errorf("mcp: stream closed before matching response arrived")
+}
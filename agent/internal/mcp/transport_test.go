@@ -0,0 +1,58 @@
+package mcp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestReadSSEResponseMatchesByID(t *testing.T) {
+	body := strings.NewReader(
+		"event: message\n" +
+			"data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"ignored\"}\n" +
+			"\n" +
+			"event: message\n" +
+			"data: {\"jsonrpc\":\"2.0\",\"id\":2,\"result\":\"wanted\"}\n" +
+			"\n",
+	)
+
+	resp, err := readSSEResponse(context.Background(), body, float64(2), discardLogger())
+	if err != nil {
+		t.Fatalf("readSSEResponse: %v", err)
+	}
+	if string(resp.Result) != `"wanted"` {
+		t.Fatalf("got result %s, want \"wanted\"", resp.Result)
+	}
+}
+
+func TestReadSSEResponseMultilineData(t *testing.T) {
+	body := strings.NewReader(
+		"data: {\"jsonrpc\":\"2.0\",\"id\":1,\n" +
+			"data: \"result\":\"multi\"}\n" +
+			"\n",
+	)
+
+	resp, err := readSSEResponse(context.Background(), body, float64(1), discardLogger())
+	if err != nil {
+		t.Fatalf("readSSEResponse: %v", err)
+	}
+	if string(resp.Result) != `"multi"` {
+		t.Fatalf("got result %s, want \"multi\"", resp.Result)
+	}
+}
+
+func TestReadSSEResponseStreamEndsWithNoMatch(t *testing.T) {
+	body := strings.NewReader(
+		"data: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"not what we want\"}\n\n",
+	)
+
+	if _, err := readSSEResponse(context.Background(), body, float64(99), discardLogger()); err == nil {
+		t.Fatal("expected an error when the stream ends with no matching id")
+	}
+}
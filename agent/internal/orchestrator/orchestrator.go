@@ -1,113 +1,259 @@
 package orchestrator
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/http"
 	"net/url"
-	"os"
-	"os/signal"
+	"sync"
 	"time"
 
+	"github.com/fabricore/agent/internal/config"
+	"github.com/fabricore/agent/internal/diagnostic"
+	"github.com/fabricore/agent/internal/jsonrpc2"
 	"github.com/fabricore/agent/internal/mcp"
 	"github.com/fabricore/agent/internal/security"
 	"github.com/fabricore/agent/internal/sys"
+	"github.com/fabricore/agent/internal/tools"
 	"github.com/fabricore/agent/internal/types"
 
 	"github.com/gorilla/websocket"
 )
 
+const heartbeatInterval = 30 * time.Second
+
 type Orchestrator struct {
 	serverURL string
 	token     string
 	agentID   string
-	conn      *websocket.Conn
+	tlsConfig *config.TLSConfig
+	log       *slog.Logger
+	rpc       *jsonrpc2.Conn
 	sys       sys.SystemOps
 	mcp       mcp.Manager
 	security  security.Manager
-	done      chan struct{}
+	registry  *tools.Registry
+	tracker   *ConnectionTracker
+	metrics   *diagnostic.Metrics
+	wsConn    *websocket.Conn
+
+	execMu     sync.Mutex
+	executions map[string]context.CancelFunc
+
+	statusMu      sync.RWMutex
+	connected     bool
+	lastHeartbeat time.Time
+	lastPingSent  time.Time
 }
 
-func New(serverURL, token string, sys sys.SystemOps, mcp mcp.Manager, sec security.Manager) *Orchestrator {
+func New(serverURL, token string, sys sys.SystemOps, mcp mcp.Manager, sec security.Manager, tlsConfig *config.TLSConfig, commandPolicy *security.CommandPolicy, metrics *diagnostic.Metrics, logger *slog.Logger) *Orchestrator {
+	registry := tools.NewRegistry()
+	registry.Register(&tools.ExecCommandTool{Policy: commandPolicy})
+	registry.Register(&tools.ProcessStartTool{Sys: sys})
+	registry.Register(&tools.ProcessListTool{Sys: sys})
+	registry.Register(&tools.ProcessSignalTool{Sys: sys})
+	registry.Register(&tools.ProcessWaitTool{Sys: sys})
+
+	if metrics == nil {
+		metrics = diagnostic.NewMetrics()
+	}
+
 	return &Orchestrator{
-		serverURL: serverURL,
-		token:     token,
-		agentID:   "agent-" + token[:8], // TODO: Generate proper ID
-		sys:       sys,
-		mcp:       mcp,
-		security:  sec,
-		done:      make(chan struct{}),
+		serverURL:  serverURL,
+		token:      token,
+		agentID:    newAgentID(logger),
+		tlsConfig:  tlsConfig,
+		log:        logger,
+		sys:        sys,
+		mcp:        mcp,
+		security:   sec,
+		registry:   registry,
+		tracker:    NewConnectionTracker(),
+		metrics:    metrics,
+		executions: make(map[string]context.CancelFunc),
+	}
+}
+
+// newAgentID generates a random identifier for this agent process, used to
+// correlate it with the server independently of its (possibly short-lived
+// or rotated) auth token.
+func newAgentID(logger *slog.Logger) string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		logger.Warn("failed to generate random agent id, using a fixed fallback", "error", err)
+		return "agent-unknown"
 	}
+	return "agent-" + hex.EncodeToString(buf)
+}
+
+// Connected reports whether the agent currently has a live connection to the
+// server. It implements diagnostic.HealthSource.
+func (o *Orchestrator) Connected() bool {
+	o.statusMu.RLock()
+	defer o.statusMu.RUnlock()
+	return o.connected
+}
+
+// LastHeartbeat returns when the last heartbeat was successfully sent, or
+// the zero time if none has been sent yet. It implements
+// diagnostic.HealthSource.
+func (o *Orchestrator) LastHeartbeat() time.Time {
+	o.statusMu.RLock()
+	defer o.statusMu.RUnlock()
+	return o.lastHeartbeat
+}
+
+func (o *Orchestrator) setConnected(connected bool) {
+	o.statusMu.Lock()
+	defer o.statusMu.Unlock()
+	o.connected = connected
+}
+
+func (o *Orchestrator) recordHeartbeat() {
+	o.statusMu.Lock()
+	defer o.statusMu.Unlock()
+	o.lastHeartbeat = time.Now()
 }
 
-func (o *Orchestrator) Start() error {
+// Tracker returns the orchestrator's in-flight work tracker, so the process
+// can drain outstanding tool executions before shutting down or handing off
+// to an upgraded binary.
+func (o *Orchestrator) Tracker() *ConnectionTracker {
+	return o.tracker
+}
+
+// Start dials the server, performs the agent.identify handshake, then serves
+// inbound calls until ctx is cancelled or the connection is lost.
+func (o *Orchestrator) Start(ctx context.Context) error {
 	u, err := url.Parse(o.serverURL)
 	if err != nil {
-		log.Printf("[ERROR] Invalid server URL: %v", err)
+		o.log.Error("invalid server URL", "error", err)
 		return err
 	}
 
-	log.Printf("[INFO] Connecting to server: %s", u.String())
-	log.Printf("[INFO] Agent ID: %s", o.agentID)
+	o.log.Info("connecting to server", "url", u.String(), "agent_id", o.agentID)
+
+	dialer, header, err := o.tlsConfig.BuildDialer(u.String())
+	if err != nil {
+		o.log.Error("refusing to dial server", "url", u.String(), "error", err)
+		return err
+	}
 
-	c, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
+	c, resp, err := dialer.Dial(u.String(), header)
 	if err != nil {
 		if resp != nil {
-			log.Printf("[ERROR] Connection failed with HTTP status: %d", resp.StatusCode)
+			o.log.Error("connection failed", "http_status", resp.StatusCode)
+			if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+				return &config.AuthError{Reason: fmt.Sprintf("server returned HTTP %d on handshake", resp.StatusCode)}
+			}
 		}
-		log.Printf("[ERROR] WebSocket dial failed: %v", err)
+		o.log.Error("websocket dial failed", "error", err)
 		return err
 	}
-	o.conn = c
 	defer c.Close()
 
-	log.Println("[OK] WebSocket connection established successfully!")
+	o.log.Info("websocket connection established")
+
+	o.wsConn = c
+	o.rpc = jsonrpc2.NewConn(jsonrpc2.NewWebSocketStream(c), jsonrpc2.HandlerFunc(o.handle))
+	o.installPingHandler(c)
 
-	// Send Handshake
-	log.Println("[INFO] Sending agent.identify handshake...")
-	if err := o.sendHandshake(); err != nil {
-		log.Printf("[ERROR] Handshake failed: %v", err)
+	o.log.Info("sending agent.identify handshake")
+	if err := o.sendHandshake(ctx); err != nil {
+		o.log.Error("handshake failed", "error", err)
+		var rpcErr *jsonrpc2.Error
+		if errors.As(err, &rpcErr) && rpcErr.Code == jsonrpc2.CodeUnauthorized {
+			return &config.AuthError{Reason: rpcErr.Message}
+		}
 		return fmt.Errorf("handshake failed: %w", err)
 	}
-	log.Println("[OK] Handshake sent successfully. Waiting for server commands...")
-
-	// Main Loop
-	interrupt := make(chan os.Signal, 1)
-	signal.Notify(interrupt, os.Interrupt)
-
-	go func() {
-		defer close(o.done)
-		for {
-			_, message, err := c.ReadMessage()
-			if err != nil {
-				log.Printf("[WARN] Read error (connection may have closed): %v", err)
-				return
-			}
-			log.Printf("[DEBUG] Received message: %s", string(message))
-			go o.handleMessage(message)
-		}
-	}()
+	o.log.Info("handshake sent, waiting for server commands")
+
+	o.setConnected(true)
+	defer o.setConnected(false)
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+
+	go o.heartbeatLoop(runCtx)
+	go o.pingLoop(runCtx, c)
+
+	runErr := make(chan error, 1)
+	go func() { runErr <- o.rpc.Run(runCtx) }()
 
 	select {
-	case <-interrupt:
-		log.Println("[INFO] Interrupt received (Ctrl+C), shutting down gracefully...")
-		err := c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
-		if err != nil {
-			log.Println("write close:", err)
-			return nil
+	case <-ctx.Done():
+		o.log.Info("shutting down gracefully")
+		_ = c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		cancelRun()
+		<-runErr
+		return nil
+	case err := <-runErr:
+		o.log.Warn("server connection closed", "error", err)
+		return err
+	}
+}
+
+// installPingHandler wires a WebSocket pong handler that measures round-trip
+// latency against the timestamp pingLoop stashes in lastPingSent, reporting
+// it to metrics.WSRoundTrip independently of the JSON-RPC layer above it.
+func (o *Orchestrator) installPingHandler(c *websocket.Conn) {
+	c.SetPongHandler(func(string) error {
+		o.statusMu.RLock()
+		sentAt := o.lastPingSent
+		o.statusMu.RUnlock()
+		if !sentAt.IsZero() {
+			o.metrics.WSRoundTrip.Observe(time.Since(sentAt).Seconds())
 		}
+		return nil
+	})
+}
+
+// pingLoop sends a WebSocket-level ping every heartbeatInterval so
+// installPingHandler's pong handler has something to time, independent of
+// the application-level agent.heartbeat notification.
+func (o *Orchestrator) pingLoop(ctx context.Context, c *websocket.Conn) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
 		select {
-		case <-o.done:
-		case <-time.After(time.Second):
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.statusMu.Lock()
+			o.lastPingSent = time.Now()
+			o.statusMu.Unlock()
+			if err := c.WriteControl(websocket.PingMessage, nil, time.Now().Add(5*time.Second)); err != nil {
+				o.log.Warn("websocket ping failed", "error", err)
+				return
+			}
 		}
-	case <-o.done:
-		log.Println("[WARN] Server connection closed unexpectedly.")
 	}
+}
 
-	return nil
+func (o *Orchestrator) heartbeatLoop(ctx context.Context) {
+	ticker := time.NewTicker(heartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := o.rpc.Notify(ctx, "agent.heartbeat", nil); err != nil {
+				o.log.Warn("heartbeat failed", "error", err)
+				return
+			}
+			o.recordHeartbeat()
+		}
+	}
 }
 
-func (o *Orchestrator) sendHandshake() error {
+func (o *Orchestrator) sendHandshake(ctx context.Context) error {
 	sysInfo := o.sys.GetSystemInfo()
 	mcpServers, _ := o.mcp.ScanLocalServers()
 	policy := o.security.GetPolicy()
@@ -125,66 +271,34 @@ func (o *Orchestrator) sendHandshake() error {
 		SecurityPolicy: policy,
 	}
 
-	// Wrap in JSONRPCRequest
-	params, _ := json.Marshal(identity)
-	req := types.JSONRPCRequest{
-		JSONRPC: "2.0",
-		Method:  "agent.identify",
-		Params:  params,
-		ID:      1,
-	}
-
-	return o.conn.WriteJSON(req)
+	var ack json.RawMessage
+	return o.rpc.Call(ctx, "agent.identify", identity, &ack)
 }
 
-func (o *Orchestrator) handleMessage(msg []byte) {
-	var req types.JSONRPCRequest
-	if err := json.Unmarshal(msg, &req); err != nil {
-		log.Printf("Failed to parse message: %v", err)
-		return
+// handle dispatches a single inbound JSON-RPC request to the matching
+// agent method. It implements jsonrpc2.Handler.
+func (o *Orchestrator) handle(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	reqLog := o.log
+	if id, ok := req.ID(); ok {
+		reqLog = reqLog.With("request_id", id)
 	}
-
-	log.Printf("Received method: %s", req.Method)
-
-	var response types.JSONRPCResponse
-	response.JSONRPC = "2.0"
-	response.ID = req.ID
+	reqLog.Debug("received method", "method", req.Method)
 
 	switch req.Method {
 	case "tool.execute":
-		result, err := o.handleToolExecute(req.Params)
-		if err != nil {
-			// Check if it's our special error type
-			if jsonErr, ok := err.(*types.JSONRPCError); ok {
-				response.Error = jsonErr
-			} else {
-				response.Error = &types.JSONRPCError{
-					Code:    -32603,
-					Message: err.Error(),
-				}
-			}
-		} else {
-			response.Result = result
-		}
+		return o.handleToolExecute(ctx, reqLog, req.Params)
+	case "tool.cancel":
+		return o.handleToolCancel(req.Params)
 	case "mcp.proxy":
-		// TODO: Implement MCP Proxy
-		result, err := o.handleMCPProxy(req.Params)
-		if err != nil {
-			response.Error = &types.JSONRPCError{Code: -32603, Message: err.Error()}
-		} else {
-			response.Result = result
-		}
+		return o.handleMCPProxy(req.Params)
+	case "agent.update_policy":
+		return o.handleUpdatePolicy(req.Params)
 	default:
-		response.Error = &types.JSONRPCError{
-			Code:    -32601,
-			Message: "Method not found",
-		}
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "Method not found"}
 	}
-
-	o.conn.WriteJSON(response)
 }
 
-func (o *Orchestrator) handleToolExecute(paramsRaw json.RawMessage) (json.RawMessage, error) {
+func (o *Orchestrator) handleToolExecute(ctx context.Context, reqLog *slog.Logger, paramsRaw json.RawMessage) (json.RawMessage, error) {
 	var params types.ToolExecuteParams
 	if err := json.Unmarshal(paramsRaw, &params); err != nil {
 		return nil, err
@@ -195,14 +309,12 @@ func (o *Orchestrator) handleToolExecute(paramsRaw json.RawMessage) (json.RawMes
 	if err != nil {
 		// UPDATED: Check for specific approval error
 		if err.Error() == "E_REQUIRES_APPROVAL" {
-			// Return JSON-RPC error with specific code -32001
-			return nil, &types.JSONRPCError{
+			return nil, &jsonrpc2.Error{
 				Code:    -32001,
 				Message: "Action requires human approval",
 				Data:    json.RawMessage(fmt.Sprintf(`{"execution_id": "%s"}`, params.ExecutionID)),
 			}
 		}
-		// Normal block
 		return nil, fmt.Errorf("security policy validation failed: %v", err)
 	}
 
@@ -210,95 +322,84 @@ func (o *Orchestrator) handleToolExecute(paramsRaw json.RawMessage) (json.RawMes
 		return nil, fmt.Errorf("security policy validation failed")
 	}
 
-	switch params.ToolName {
-	case "exec_command":
-		var args struct {
-			Command string   `json:"command"`
-			Args    []string `json:"args"`
-			Timeout int      `json:"timeout"`
-		}
-		if err := json.Unmarshal(params.Arguments, &args); err != nil {
-			return nil, err
+	var args map[string]interface{}
+	if err := json.Unmarshal(params.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	done := o.tracker.Begin()
+	defer done()
+
+	execCtx, cancel := context.WithCancel(ctx)
+	o.registerExecution(params.ExecutionID, cancel)
+	defer o.finishExecution(params.ExecutionID)
+
+	progress := func(chunk interface{}) {
+		if err := o.rpc.Notify(context.Background(), "tool.progress", map[string]interface{}{
+			"execution_id": params.ExecutionID,
+			"chunk":        chunk,
+		}); err != nil {
+			reqLog.Warn("failed to send tool.progress", "execution_id", params.ExecutionID, "error", err)
 		}
-		output, err := o.sys.ExecCommand(args.Command, args.Args, args.Timeout)
-		if err != nil {
-			return nil, err
+	}
+
+	reqLog.Info("executing tool", "tool", params.ToolName, "execution_id", params.ExecutionID)
+	defer diagnostic.Time(o.metrics.CommandDuration)()
+	o.metrics.CommandsExecuted.Inc()
+	result, err := o.registry.ExecuteTool(execCtx, params.ToolName, args, progress)
+	if err != nil {
+		o.metrics.CommandFailures.Inc()
+		if execCtx.Err() == context.Canceled {
+			return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeCancelled, Message: "Execution cancelled"}
 		}
-		return json.Marshal(map[string]string{"output": output})
-	default:
-		return nil, fmt.Errorf("unknown tool: %s", params.ToolName)
+		return nil, err
 	}
+	return json.Marshal(result)
 }
 
-// ADD THIS NEW FUNCTION
-func (o *Orchestrator) handleUpdatePolicy(paramsRaw json.RawMessage) (json.RawMessage, error) {
+func (o *Orchestrator) handleToolCancel(paramsRaw json.RawMessage) (json.RawMessage, error) {
 	var params struct {
-		Policy types.SecurityPolicy `json:"policy"`
+		ExecutionID string `json:"execution_id"`
 	}
 	if err := json.Unmarshal(paramsRaw, &params); err != nil {
 		return nil, err
 	}
 
-	// Apply the policy
-	o.security.UpdatePolicy(params.Policy)
-	log.Printf("[INFO] Security policy updated. Rules: %d", len(params.Policy.Rules))
+	o.execMu.Lock()
+	cancel, ok := o.executions[params.ExecutionID]
+	o.execMu.Unlock()
+	if !ok {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "unknown execution_id"}
+	}
 
-	return json.Marshal(map[string]string{"status": "updated"})
+	cancel()
+	return json.Marshal(map[string]string{"status": "cancelling"})
 }
 
-// UPDATE THE SWITCH STATEMENT
-func (o *Orchestrator) handleMessageNew(msg []byte) {
-	var req types.JSONRPCRequest
-	if err := json.Unmarshal(msg, &req); err != nil {
-		log.Printf("Failed to parse message: %v", err)
-		return
-	}
-
-	log.Printf("Received method: %s", req.Method)
+func (o *Orchestrator) registerExecution(id string, cancel context.CancelFunc) {
+	o.execMu.Lock()
+	defer o.execMu.Unlock()
+	o.executions[id] = cancel
+}
 
-	var response types.JSONRPCResponse
-	response.JSONRPC = "2.0"
-	response.ID = req.ID
+func (o *Orchestrator) finishExecution(id string) {
+	o.execMu.Lock()
+	defer o.execMu.Unlock()
+	delete(o.executions, id)
+}
 
-	switch req.Method {
-	case "tool.execute":
-		result, err := o.handleToolExecute(req.Params)
-		if err != nil {
-			// Check if it's our special error type
-			if jsonErr, ok := err.(*types.JSONRPCError); ok {
-				response.Error = jsonErr
-			} else {
-				response.Error = &types.JSONRPCError{
-					Code:    -32603,
-					Message: err.Error(),
-				}
-			}
-		} else {
-			response.Result = result
-		}
-	case "mcp.proxy":
-		// TODO: Implement MCP Proxy
-		result, err := o.handleMCPProxy(req.Params)
-		if err != nil {
-			response.Error = &types.JSONRPCError{Code: -32603, Message: err.Error()}
-		} else {
-			response.Result = result
-		}
-	case "agent.update_policy":
-		result, err := o.handleUpdatePolicy(req.Params)
-		if err != nil {
-			response.Error = &types.JSONRPCError{Code: -32603, Message: err.Error()}
-		} else {
-			response.Result = result
-		}
-	default:
-		response.Error = &types.JSONRPCError{
-			Code:    -32601,
-			Message: "Method not found",
-		}
+func (o *Orchestrator) handleUpdatePolicy(paramsRaw json.RawMessage) (json.RawMessage, error) {
+	var params struct {
+		Policy types.SecurityPolicy `json:"policy"`
 	}
+	if err := json.Unmarshal(paramsRaw, &params); err != nil {
+		return nil, err
+	}
+
+	o.security.UpdatePolicy(params.Policy)
+	o.log.Info("security policy updated", "rules", len(params.Policy.Rules))
 
-	o.conn.WriteJSON(response)
+	return json.Marshal(map[string]string{"status": "updated"})
 }
 
 func (o *Orchestrator) handleMCPProxy(paramsRaw json.RawMessage) (json.RawMessage, error) {
@@ -307,6 +408,10 @@ func (o *Orchestrator) handleMCPProxy(paramsRaw json.RawMessage) (json.RawMessag
 		return nil, err
 	}
 
+	done := o.tracker.Begin()
+	defer done()
+
+	o.metrics.MCPCalls.Inc()
 	resp, err := o.mcp.ProxyRequest(params.TargetServer, params.InnerRequest)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,67 @@
+package orchestrator
+
+import (
+	"context"
+	"sync"
+)
+
+// ConnectionTracker reference-counts in-flight work (tool executions, MCP
+// proxy calls) so a draining process knows when it's safe to exit without
+// dropping anything the server asked it to do.
+type ConnectionTracker struct {
+	mu     sync.Mutex
+	active int
+	idleCh chan struct{} // closed while active == 0, replaced on the 0->1 transition
+}
+
+// NewConnectionTracker returns a tracker that starts idle.
+func NewConnectionTracker() *ConnectionTracker {
+	t := &ConnectionTracker{idleCh: make(chan struct{})}
+	close(t.idleCh)
+	return t
+}
+
+// Begin marks one unit of work as started and returns a func to call when
+// it finishes. The returned func is safe to call more than once.
+func (t *ConnectionTracker) Begin() func() {
+	t.mu.Lock()
+	if t.active == 0 {
+		t.idleCh = make(chan struct{})
+	}
+	t.active++
+	t.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			t.active--
+			if t.active == 0 {
+				close(t.idleCh)
+			}
+			t.mu.Unlock()
+		})
+	}
+}
+
+// Count returns the number of units of work currently in flight.
+func (t *ConnectionTracker) Count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.active
+}
+
+// Drain blocks until no work is in flight or ctx is done, whichever comes
+// first.
+func (t *ConnectionTracker) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	ch := t.idleCh
+	t.mu.Unlock()
+
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
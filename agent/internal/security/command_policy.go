@@ -0,0 +1,222 @@
+package security
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultShellMetachars are the characters a shell would treat specially if
+// this argv were ever re-interpreted by one. CommandPolicy rejects them in
+// arguments whenever a command is run with shell=false, on the theory that a
+// legitimate non-shell invocation never needs them and their presence is a
+// sign something downstream (a logging pipeline, a later shell wrapper) may
+// reinterpret the command in a way the allowlist didn't account for.
+var defaultShellMetachars = []rune{';', '&', '|', '$', '`', '(', ')', '<', '>', '\n', '"', '\'', '*', '?', '~', '#', '!', '\\'}
+
+// AllowedCommand is one entry in a CommandPolicy's allowlist: a binary this
+// agent is permitted to run, identified by basename after path resolution,
+// with optional content pinning and argument constraints.
+type AllowedCommand struct {
+	// Basename is the resolved binary's filename, e.g. "systemctl". Command
+	// lookup follows PATH the same way exec.LookPath does, so an allowlist
+	// entry can't be bypassed by invoking the same binary via a different
+	// absolute path.
+	Basename string `yaml:"basename"`
+	// SHA256 optionally pins the resolved binary's content hash (hex), so a
+	// compromised PATH entry with the right name still can't substitute
+	// arbitrary code.
+	SHA256 string `yaml:"sha256,omitempty"`
+	// ArgPattern optionally constrains the joined argument list to a
+	// regular expression, e.g. restricting a "systemctl" entry to
+	// `^(status|restart) [a-z0-9_-]+$`.
+	ArgPattern string `yaml:"arg_pattern,omitempty"`
+}
+
+// ResourceLimits caps the resources a command's child process may consume.
+// Zero means "don't apply this limit".
+type ResourceLimits struct {
+	CPUSeconds        uint64 `yaml:"cpu_seconds,omitempty"`
+	AddressSpaceBytes uint64 `yaml:"address_space_bytes,omitempty"`
+	MaxOpenFiles      uint64 `yaml:"max_open_files,omitempty"`
+}
+
+// RunAs drops a command's child process to an unprivileged uid/gid instead
+// of inheriting the agent's own credentials.
+type RunAs struct {
+	UID uint32 `yaml:"uid"`
+	GID uint32 `yaml:"gid"`
+}
+
+// CommandPolicy is the agent's allowlist and sandboxing policy for
+// RealSystem.ExecCommand, loaded once at startup from a signed YAML file so
+// a compromised server-issued token can't turn into arbitrary code
+// execution: the server can only ask the agent to run what this policy
+// already permits.
+type CommandPolicy struct {
+	Allowlist []AllowedCommand `yaml:"allowlist"`
+	Limits    ResourceLimits   `yaml:"resource_limits"`
+	RunAs     *RunAs           `yaml:"run_as,omitempty"`
+}
+
+// LoadCommandPolicy reads policyPath, verifies it against the detached
+// signature in sigPath using the hex-encoded ed25519 public key in
+// pubKeyPath, and parses it as YAML. It refuses to return a policy whose
+// signature doesn't verify, since an unsigned or tampered policy file is
+// worse than no policy at all: a server that can also write to disk could
+// otherwise loosen its own sandbox.
+func LoadCommandPolicy(policyPath, sigPath, pubKeyPath string) (*CommandPolicy, error) {
+	raw, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading command policy %s: %w", policyPath, err)
+	}
+	sigHex, err := os.ReadFile(sigPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading command policy signature %s: %w", sigPath, err)
+	}
+	pubHex, err := os.ReadFile(pubKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading command policy public key %s: %w", pubKeyPath, err)
+	}
+
+	sig, err := hex.DecodeString(strings.TrimSpace(string(sigHex)))
+	if err != nil {
+		return nil, fmt.Errorf("command policy signature is not valid hex: %w", err)
+	}
+	pub, err := hex.DecodeString(strings.TrimSpace(string(pubHex)))
+	if err != nil {
+		return nil, fmt.Errorf("command policy public key is not valid hex: %w", err)
+	}
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("command policy public key is %d bytes, want %d", len(pub), ed25519.PublicKeySize)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), raw, sig) {
+		return nil, fmt.Errorf("command policy signature verification failed")
+	}
+
+	var policy CommandPolicy
+	if err := yaml.Unmarshal(raw, &policy); err != nil {
+		return nil, fmt.Errorf("parsing command policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// Validate checks cmd and args against the allowlist before the caller is
+// allowed to build an *exec.Cmd out of them. shell indicates whether the
+// caller will hand the result to a shell (e.g. "sh -c"); when false, args
+// are additionally checked for shell metacharacters, since a non-shell
+// invocation should never need them.
+func (p *CommandPolicy) Validate(cmd string, args []string, shell bool) error {
+	resolved := cmd
+	if !strings.ContainsRune(cmd, filepath.Separator) {
+		lookedUp, err := exec.LookPath(cmd)
+		if err != nil {
+			return fmt.Errorf("resolving %q: %w", cmd, err)
+		}
+		resolved = lookedUp
+	}
+	basename := filepath.Base(resolved)
+
+	entry, ok := p.lookup(basename)
+	if !ok {
+		return fmt.Errorf("%q is not in the command allowlist", basename)
+	}
+
+	if entry.SHA256 != "" {
+		sum, err := sha256File(resolved)
+		if err != nil {
+			return fmt.Errorf("hashing %q: %w", resolved, err)
+		}
+		if !strings.EqualFold(sum, entry.SHA256) {
+			return fmt.Errorf("%q content hash %s does not match the pinned %s", resolved, sum, entry.SHA256)
+		}
+	}
+
+	if entry.ArgPattern != "" {
+		matched, err := regexp.MatchString(entry.ArgPattern, strings.Join(args, " "))
+		if err != nil {
+			return fmt.Errorf("invalid arg_pattern for %q: %w", basename, err)
+		}
+		if !matched {
+			return fmt.Errorf("arguments to %q do not match the allowed pattern", basename)
+		}
+	}
+
+	if !shell {
+		for _, arg := range args {
+			if c, bad := containsShellMetachar(arg); bad {
+				return fmt.Errorf("argument to %q contains disallowed shell metacharacter %q", basename, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Prepare configures cmd's SysProcAttr for RunAs before it's started. It's a
+// no-op if the policy doesn't configure RunAs.
+func (p *CommandPolicy) Prepare(cmd *exec.Cmd) {
+	if p.RunAs != nil {
+		prepareCredential(cmd, p.RunAs)
+	}
+}
+
+// PrepareExec rewrites name/args into what the caller should actually pass
+// to exec.Command (plus any extra environment variables to set on the
+// resulting *exec.Cmd) so the policy's resource limits take effect before
+// the target's first instruction runs. Call it after Validate and before
+// building the *exec.Cmd; it returns name/args unchanged, and a nil env,
+// when the policy has no resource_limits configured.
+//
+// This exists instead of a post-start Prlimit(pid, ...) call because by the
+// time cmd.Start() returns, the child has already completed its fork+execve
+// (that's how os/exec detects exec failures): any limit installed after
+// Start leaves the untrusted binary, and anything it forks in that window,
+// running fully unconstrained for an arbitrary scheduling delay. Where
+// pre-exec wrapping is implemented (see command_policy_linux.go), the
+// returned name/args re-exec the agent's own binary as a trampoline that
+// installs the limits on itself and then execve's into the real target, so
+// they're in place before it runs at all.
+func (p *CommandPolicy) PrepareExec(name string, args []string) (string, []string, []string, error) {
+	if p.Limits == (ResourceLimits{}) {
+		return name, args, nil, nil
+	}
+	return prepareLimitWrapper(name, args, p.Limits)
+}
+
+func (p *CommandPolicy) lookup(basename string) (AllowedCommand, bool) {
+	for _, entry := range p.Allowlist {
+		if entry.Basename == basename {
+			return entry, true
+		}
+	}
+	return AllowedCommand{}, false
+}
+
+func containsShellMetachar(arg string) (string, bool) {
+	for _, r := range arg {
+		for _, bad := range defaultShellMetachars {
+			if r == bad {
+				return string(r), true
+			}
+		}
+	}
+	return "", false
+}
+
+func sha256File(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,111 @@
+//go:build linux
+
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// execWrapperArg0 is the sentinel argv[1] that tells a freshly re-exec'd
+// agent binary to act as a resource-limit trampoline (see
+// RunExecWrapperIfInvoked) instead of starting the agent normally.
+const execWrapperArg0 = "__fabricore_exec_wrapper__"
+
+// execWrapperLimitsEnv carries the trampoline's ResourceLimits, JSON-encoded,
+// across the re-exec hop. An env var (rather than extra argv ahead of the
+// real command) keeps the wrapped argv's tail identical to what
+// CommandPolicy.Validate already checked against the allowlist's
+// arg_pattern.
+const execWrapperLimitsEnv = "FABRICORE_EXEC_LIMITS"
+
+// prepareLimitWrapper rewrites name/args so that starting them re-execs the
+// agent's own binary as a trampoline: RunExecWrapperIfInvoked applies limits
+// to itself via setrlimit(2) -- which, unlike prlimit(2), only ever affects
+// the calling process -- and then execve's into the real target, which
+// inherits the already-applied limits across the exec. This closes the race
+// a post-start prlimit(2) call leaves open between cmd.Start() returning and
+// the child's own execve.
+func prepareLimitWrapper(name string, args []string, limits ResourceLimits) (string, []string, []string, error) {
+	self, err := os.Executable()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("resolving agent executable for resource-limit wrapper: %w", err)
+	}
+	encoded, err := json.Marshal(limits)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("encoding resource limits: %w", err)
+	}
+	wrapped := append([]string{execWrapperArg0, name}, args...)
+	env := []string{execWrapperLimitsEnv + "=" + string(encoded)}
+	return self, wrapped, env, nil
+}
+
+// RunExecWrapperIfInvoked checks whether this process was started as a
+// resource-limit trampoline (via prepareLimitWrapper) and, if so, applies
+// the requested limits to itself and execve's into the real target. It
+// never returns if invoked as a trampoline: on success the process image is
+// replaced by the real target, and on failure it reports the error to
+// stderr and exits non-zero. Callers must invoke this as the very first
+// thing in main(), before flag parsing or any other startup work, since a
+// trampoline re-exec must not do anything the real agent process would do.
+func RunExecWrapperIfInvoked() {
+	if len(os.Args) < 3 || os.Args[1] != execWrapperArg0 {
+		return
+	}
+
+	var limits ResourceLimits
+	if raw := os.Getenv(execWrapperLimitsEnv); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &limits); err != nil {
+			fmt.Fprintf(os.Stderr, "fabricore exec wrapper: decoding %s: %v\n", execWrapperLimitsEnv, err)
+			os.Exit(1)
+		}
+	}
+
+	if err := setSelfRlimits(limits); err != nil {
+		fmt.Fprintf(os.Stderr, "fabricore exec wrapper: applying resource limits: %v\n", err)
+		os.Exit(1)
+	}
+
+	target := os.Args[2]
+	targetArgs := os.Args[2:]
+	resolved := target
+	if !strings.ContainsRune(target, os.PathSeparator) {
+		lookedUp, err := exec.LookPath(target)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "fabricore exec wrapper: resolving %q: %v\n", target, err)
+			os.Exit(1)
+		}
+		resolved = lookedUp
+	}
+
+	os.Unsetenv(execWrapperLimitsEnv)
+	if err := syscall.Exec(resolved, targetArgs, os.Environ()); err != nil {
+		fmt.Fprintf(os.Stderr, "fabricore exec wrapper: exec %q: %v\n", target, err)
+		os.Exit(1)
+	}
+}
+
+func setSelfRlimits(limits ResourceLimits) error {
+	if limits.CPUSeconds > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_CPU, &unix.Rlimit{Cur: limits.CPUSeconds, Max: limits.CPUSeconds}); err != nil {
+			return fmt.Errorf("RLIMIT_CPU: %w", err)
+		}
+	}
+	if limits.AddressSpaceBytes > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_AS, &unix.Rlimit{Cur: limits.AddressSpaceBytes, Max: limits.AddressSpaceBytes}); err != nil {
+			return fmt.Errorf("RLIMIT_AS: %w", err)
+		}
+	}
+	if limits.MaxOpenFiles > 0 {
+		if err := unix.Setrlimit(unix.RLIMIT_NOFILE, &unix.Rlimit{Cur: limits.MaxOpenFiles, Max: limits.MaxOpenFiles}); err != nil {
+			return fmt.Errorf("RLIMIT_NOFILE: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+//go:build !linux
+
+package security
+
+import "fmt"
+
+// prepareLimitWrapper is only implemented on Linux (see
+// command_policy_linux.go), which re-execs the agent binary as a
+// setrlimit(2) trampoline before the target runs. darwin/bsd could in
+// principle do the same (setrlimit there also only ever applies to the
+// calling process), and Windows would need a different integration
+// entirely (job objects), but neither is wired up yet. A policy with
+// resource_limits configured is honored on Linux; elsewhere this returns an
+// error rather than returning the command unwrapped as if the limits
+// applied, and each caller decides what to do with that error.
+func prepareLimitWrapper(name string, args []string, limits ResourceLimits) (string, []string, []string, error) {
+	if limits == (ResourceLimits{}) {
+		return name, args, nil, nil
+	}
+	return "", nil, nil, fmt.Errorf("security: resource limits are not supported on this platform")
+}
+
+// RunExecWrapperIfInvoked is a no-op outside Linux: prepareLimitWrapper
+// never produces the trampoline's sentinel argv on this platform, so there's
+// nothing for a re-exec'd process to detect.
+func RunExecWrapperIfInvoked() {}
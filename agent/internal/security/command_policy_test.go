@@ -0,0 +1,68 @@
+package security
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateRejectsCommandNotInAllowlist(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo"}}}
+	if err := p.Validate("cat", nil, false); err == nil {
+		t.Fatal("expected an error for a command not in the allowlist")
+	}
+}
+
+func TestValidateAllowsAllowlistedCommand(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo"}}}
+	if err := p.Validate("echo", []string{"hello"}, false); err != nil {
+		t.Fatalf("expected echo to be allowed, got: %v", err)
+	}
+}
+
+func TestValidateRejectsWrongSHA256(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo", SHA256: "0000000000000000000000000000000000000000000000000000000000000000"}}}
+	if err := p.Validate("echo", nil, false); err == nil {
+		t.Fatal("expected an error for a mismatched sha256 pin")
+	}
+}
+
+func TestValidateAcceptsCorrectSHA256(t *testing.T) {
+	resolved, err := exec.LookPath("echo")
+	if err != nil {
+		t.Skipf("echo not found on PATH: %v", err)
+	}
+	sum, err := sha256File(resolved)
+	if err != nil {
+		t.Fatalf("hashing echo: %v", err)
+	}
+
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: filepath.Base(resolved), SHA256: sum}}}
+	if err := p.Validate("echo", nil, false); err != nil {
+		t.Fatalf("expected echo to be allowed with a matching sha256 pin, got: %v", err)
+	}
+}
+
+func TestValidateEnforcesArgPattern(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo", ArgPattern: `^hello$`}}}
+	if err := p.Validate("echo", []string{"hello"}, false); err != nil {
+		t.Fatalf("expected matching args to be allowed, got: %v", err)
+	}
+	if err := p.Validate("echo", []string{"goodbye"}, false); err == nil {
+		t.Fatal("expected an error for args that don't match arg_pattern")
+	}
+}
+
+func TestValidateRejectsShellMetacharsWhenNotShell(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo"}}}
+	if err := p.Validate("echo", []string{"hi; rm -rf /"}, false); err == nil {
+		t.Fatal("expected an error for a shell metacharacter in a non-shell invocation")
+	}
+}
+
+func TestValidateAllowsShellMetacharsWhenShell(t *testing.T) {
+	p := &CommandPolicy{Allowlist: []AllowedCommand{{Basename: "echo"}}}
+	if err := p.Validate("echo", []string{"hi; echo again"}, true); err != nil {
+		t.Fatalf("expected shell=true to skip the metacharacter check, got: %v", err)
+	}
+}
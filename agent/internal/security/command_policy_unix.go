@@ -0,0 +1,20 @@
+//go:build unix
+
+package security
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// prepareCredential drops cmd's child process to runAs's uid/gid via
+// SysProcAttr.Credential, the standard os/exec mechanism on POSIX systems.
+func prepareCredential(cmd *exec.Cmd, runAs *RunAs) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid: runAs.UID,
+		Gid: runAs.GID,
+	}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package security
+
+import "os/exec"
+
+// prepareCredential is unimplemented on Windows: dropping to another
+// account requires a logon token (syscall.Token), which means authenticating
+// as that account up front, not just naming a uid/gid. Policies with RunAs
+// configured simply won't drop privileges on this platform; PrepareExec
+// likewise returns an error for resource limits rather than ignoring them
+// (sys.RealSystem logs that error; tools.ExecCommandTool does not).
+func prepareCredential(cmd *exec.Cmd, runAs *RunAs) {}
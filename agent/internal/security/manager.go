@@ -3,22 +3,29 @@ package security
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"regexp"
+	"sync"
 
 	"github.com/fabricore/agent/internal/types"
 )
 
 type Manager interface {
-	ValidateAction(toolName string, args interface{}) (bool, error)
+	ValidateAction(toolName string, args interface{}, approvedBy string) (bool, error)
 	GetPolicy() types.SecurityPolicy
+	UpdatePolicy(policy types.SecurityPolicy)
 }
 
 type RealManager struct {
+	log *slog.Logger
+
+	mu     sync.RWMutex
 	policy types.SecurityPolicy
 }
 
-func NewManager() *RealManager {
+func NewManager(logger *slog.Logger) *RealManager {
 	return &RealManager{
+		log: logger,
 		policy: types.SecurityPolicy{
 			Rules: []types.SecurityRule{
 				{ToolName: "exec_command", ArgPattern: "^rm -rf /$", Action: "block"},
@@ -32,7 +39,13 @@ func NewManager() *RealManager {
 	}
 }
 
-func (m *RealManager) ValidateAction(toolName string, args interface{}) (bool, error) {
+// ValidateAction checks args for toolName against the current policy.
+// approvedBy carries the identity of whoever signed off on a
+// "require_approval" rule (e.g. from ToolExecuteParams.ApprovedBy); a
+// non-empty value satisfies the rule instead of returning E_REQUIRES_APPROVAL,
+// so the orchestrator doesn't have to re-implement policy matching itself
+// just to honor an approval that already happened upstream.
+func (m *RealManager) ValidateAction(toolName string, args interface{}, approvedBy string) (bool, error) {
 	// Convert args to string for regex matching
 	// For exec_command, args is a struct, but we need the command string
 	var argsStr string
@@ -45,8 +58,12 @@ func (m *RealManager) ValidateAction(toolName string, args interface{}) (bool, e
 		argsStr = string(bytes)
 	}
 
+	m.mu.RLock()
+	policy := m.policy
+	m.mu.RUnlock()
+
 	// 1. Iterate through Rules
-	for _, rule := range m.policy.Rules {
+	for _, rule := range policy.Rules {
 		if rule.ToolName == toolName {
 			matched, err := regexp.MatchString(rule.ArgPattern, argsStr)
 			if err != nil {
@@ -56,12 +73,14 @@ func (m *RealManager) ValidateAction(toolName string, args interface{}) (bool, e
 			if matched {
 				switch rule.Action {
 				case "block":
+					m.log.Warn("action blocked by security policy", "tool", toolName, "pattern", rule.ArgPattern)
 					return false, fmt.Errorf("action blocked by security policy")
 				case "require_approval":
-					// We need to check if approval is present.
-					// Since ValidateAction interface signature currently doesn't accept the full context (like params),
-					// we are limited here. However, the Orchestrator calls this.
-					// The Orchestrator should interpret a specific error from here.
+					if approvedBy != "" {
+						m.log.Debug("action approved", "tool", toolName, "pattern", rule.ArgPattern, "approved_by", approvedBy)
+						return true, nil
+					}
+					m.log.Debug("action requires approval", "tool", toolName, "pattern", rule.ArgPattern)
 					return false, fmt.Errorf("E_REQUIRES_APPROVAL")
 				case "allow":
 					return true, nil
@@ -71,7 +90,8 @@ func (m *RealManager) ValidateAction(toolName string, args interface{}) (bool, e
 	}
 
 	// 2. Default Action
-	if m.policy.Default == "block" {
+	if policy.Default == "block" {
+		m.log.Warn("action blocked by default policy", "tool", toolName)
 		return false, fmt.Errorf("action blocked by default policy")
 	}
 
@@ -79,5 +99,16 @@ func (m *RealManager) ValidateAction(toolName string, args interface{}) (bool, e
 }
 
 func (m *RealManager) GetPolicy() types.SecurityPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
 	return m.policy
 }
+
+// UpdatePolicy replaces the active security policy wholesale, as requested
+// by an "agent.update_policy" RPC. It takes effect for every ValidateAction
+// call after it returns; in-flight validations may still see the old policy.
+func (m *RealManager) UpdatePolicy(policy types.SecurityPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.policy = policy
+}
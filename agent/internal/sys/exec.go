@@ -0,0 +1,167 @@
+package sys
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// outputBufferLines caps how many trailing lines of stdout/stderr each
+// ExecHandle retains. Older lines are dropped as new ones arrive, since
+// there may be no MCP tool call reading the output of a long-running
+// process for a while, and drain must keep reading without waiting on a
+// reader: stopping would stall the child's pipe and mean cmd.Wait (and
+// reaping) never completes.
+const outputBufferLines = 1000
+
+// ExecHandle is a running child process, modeled after containerd's process
+// API: a Pid, buffered stdout/stderr, and lifecycle controls (Signal,
+// Pause, Resume, Wait) in place of ExecCommand's buffered run-to-completion
+// output.
+type ExecHandle struct {
+	Pid     int
+	Command string
+	Args    []string
+
+	cmd     *exec.Cmd
+	done    chan struct{}
+	waitErr error
+
+	outMu  sync.Mutex
+	stdout []string
+	stderr []string
+}
+
+// startExecProcess starts name as a child process. extraEnv, if non-empty,
+// is appended to the process's inherited environment (e.g. to pass a
+// security.CommandPolicy resource-limit wrapper's state across a re-exec
+// hop). prepare, if non-nil, is called on the *exec.Cmd before Start so a
+// caller can apply policy (e.g. security.CommandPolicy.Prepare) that must be
+// set up ahead of time.
+func startExecProcess(name string, args []string, extraEnv []string, prepare func(*exec.Cmd)) (*ExecHandle, error) {
+	cmd := exec.Command(name, args...)
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if prepare != nil {
+		prepare(cmd)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	h := &ExecHandle{
+		Pid:     cmd.Process.Pid,
+		Command: name,
+		Args:    args,
+		cmd:     cmd,
+		done:    make(chan struct{}),
+	}
+
+	var pipesDone sync.WaitGroup
+	pipesDone.Add(2)
+	go h.drain(&pipesDone, stdout, &h.stdout)
+	go h.drain(&pipesDone, stderr, &h.stderr)
+
+	go func() {
+		// exec.Cmd.Wait requires both pipes to be fully drained first.
+		pipesDone.Wait()
+		h.waitErr = cmd.Wait()
+		close(h.done)
+	}()
+
+	return h, nil
+}
+
+// drain reads r line by line into the bounded buffer dst, trimming the
+// oldest lines once outputBufferLines is exceeded. It always runs to EOF on
+// its own, so a caller that never asks for Stdout/Stderr can't stall the
+// child's pipe.
+//
+// It uses bufio.Reader.ReadString rather than bufio.Scanner: Scanner's
+// default 64KB max token size means a single line over that would stop the
+// scan with no error checked here, after which nothing drains the pipe
+// further -- a chatty child can then fill its OS pipe buffer and hang
+// instead of exiting. ReadString has no such cap.
+func (h *ExecHandle) drain(wg *sync.WaitGroup, r io.Reader, dst *[]string) {
+	defer wg.Done()
+
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			h.outMu.Lock()
+			*dst = append(*dst, strings.TrimSuffix(line, "\n"))
+			if len(*dst) > outputBufferLines {
+				*dst = (*dst)[len(*dst)-outputBufferLines:]
+			}
+			h.outMu.Unlock()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// Stdout returns a snapshot of the process's stdout, up to the trailing
+// outputBufferLines lines.
+func (h *ExecHandle) Stdout() []string {
+	h.outMu.Lock()
+	defer h.outMu.Unlock()
+	return append([]string(nil), h.stdout...)
+}
+
+// Stderr returns a snapshot of the process's stderr, up to the trailing
+// outputBufferLines lines.
+func (h *ExecHandle) Stderr() []string {
+	h.outMu.Lock()
+	defer h.outMu.Unlock()
+	return append([]string(nil), h.stderr...)
+}
+
+// Wait blocks until the process exits and returns its exit error, if any.
+// It's safe to call more than once.
+func (h *ExecHandle) Wait() error {
+	<-h.done
+	return h.waitErr
+}
+
+// Signal delivers sig to the process.
+func (h *ExecHandle) Signal(sig os.Signal) error {
+	return h.cmd.Process.Signal(sig)
+}
+
+// Pause suspends the process without killing it (SIGSTOP on Unix).
+func (h *ExecHandle) Pause() error {
+	return pauseProcess(h.Pid)
+}
+
+// Resume continues a paused process (SIGCONT on Unix).
+func (h *ExecHandle) Resume() error {
+	return resumeProcess(h.Pid)
+}
+
+// ExitCode returns the process's exit code once Wait has returned, or -1 if
+// it's still running or exited abnormally (signal, launch failure).
+func (h *ExecHandle) ExitCode() int {
+	select {
+	case <-h.done:
+		return h.cmd.ProcessState.ExitCode()
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package sys
+
+import "syscall"
+
+func pauseProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGSTOP)
+}
+
+func resumeProcess(pid int) error {
+	return syscall.Kill(pid, syscall.SIGCONT)
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package sys
+
+import "fmt"
+
+// Windows has no SIGSTOP/SIGCONT equivalent reachable without
+// NtSuspendProcess/NtResumeProcess (undocumented ntdll calls); rather than
+// depend on those, we report pause/resume as unsupported here.
+func pauseProcess(pid int) error {
+	return fmt.Errorf("sys: pausing a process is not supported on windows")
+}
+
+func resumeProcess(pid int) error {
+	return fmt.Errorf("sys: resuming a process is not supported on windows")
+}
@@ -0,0 +1,98 @@
+package sys
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// ProcessInfo is a snapshot of a registered process, for listing over the
+// MCP proxy without handing out the live ExecHandle.
+type ProcessInfo struct {
+	Pid     int      `json:"pid"`
+	Command string   `json:"command"`
+	Args    []string `json:"args"`
+}
+
+// ProcessRegistry tracks processes started via RealSystem.ExecProcess so
+// MCP tools can list, signal, and reap them by pid.
+type ProcessRegistry struct {
+	mu    sync.Mutex
+	procs map[int]*ExecHandle
+}
+
+// NewProcessRegistry returns an empty registry.
+func NewProcessRegistry() *ProcessRegistry {
+	return &ProcessRegistry{procs: make(map[int]*ExecHandle)}
+}
+
+func (r *ProcessRegistry) add(h *ExecHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.procs[h.Pid] = h
+}
+
+func (r *ProcessRegistry) remove(pid int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.procs, pid)
+}
+
+// Get returns the handle for pid, if it's still registered.
+func (r *ProcessRegistry) Get(pid int) (*ExecHandle, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	h, ok := r.procs[pid]
+	return h, ok
+}
+
+// List returns a snapshot of every currently-registered process.
+func (r *ProcessRegistry) List() []ProcessInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	infos := make([]ProcessInfo, 0, len(r.procs))
+	for _, h := range r.procs {
+		infos = append(infos, ProcessInfo{Pid: h.Pid, Command: h.Command, Args: h.Args})
+	}
+	return infos
+}
+
+// Signal delivers sig to the registered process with pid.
+func (r *ProcessRegistry) Signal(pid int, sig os.Signal) error {
+	h, ok := r.Get(pid)
+	if !ok {
+		return fmt.Errorf("sys: no registered process with pid %d", pid)
+	}
+	return h.Signal(sig)
+}
+
+// Pause suspends the registered process with pid.
+func (r *ProcessRegistry) Pause(pid int) error {
+	h, ok := r.Get(pid)
+	if !ok {
+		return fmt.Errorf("sys: no registered process with pid %d", pid)
+	}
+	return h.Pause()
+}
+
+// Resume continues a paused, registered process with pid.
+func (r *ProcessRegistry) Resume(pid int) error {
+	h, ok := r.Get(pid)
+	if !ok {
+		return fmt.Errorf("sys: no registered process with pid %d", pid)
+	}
+	return h.Resume()
+}
+
+// Reap waits for the registered process with pid to exit and returns its
+// exit code. Reap does not remove the process itself: ExecProcess removes
+// it from the registry once it exits.
+func (r *ProcessRegistry) Reap(pid int) (int, error) {
+	h, ok := r.Get(pid)
+	if !ok {
+		return -1, fmt.Errorf("sys: no registered process with pid %d", pid)
+	}
+	err := h.Wait()
+	return h.ExitCode(), err
+}
@@ -0,0 +1,30 @@
+//go:build freebsd || netbsd || openbsd || dragonfly
+
+package sys
+
+import (
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func platformInfo() platformStats {
+	stats := platformStats{CPUCount: runtime.NumCPU()}
+
+	if release, err := unix.Sysctl("kern.osrelease"); err == nil {
+		stats.Release = release
+	}
+
+	if boot, err := unix.SysctlTimeval("kern.boottime"); err == nil {
+		stats.BootTimeUnix = boot.Sec
+		stats.UptimeSeconds = uint64(time.Now().Unix() - boot.Sec)
+	}
+
+	// Disk and memory stats are left at 0 here: Statfs_t's field names (and
+	// hw.physmem's width) diverge across freebsd/netbsd/openbsd/dragonfly,
+	// so reporting them accurately needs a dedicated file per variant
+	// rather than one guess that's wrong on some of them.
+
+	return stats
+}
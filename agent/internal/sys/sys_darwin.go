@@ -0,0 +1,37 @@
+//go:build darwin
+
+package sys
+
+import (
+	"runtime"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func platformInfo() platformStats {
+	stats := platformStats{CPUCount: runtime.NumCPU()}
+
+	if release, err := unix.Sysctl("kern.osrelease"); err == nil {
+		stats.Release = release
+	}
+
+	if boot, err := unix.SysctlTimeval("kern.boottime"); err == nil {
+		stats.BootTimeUnix = boot.Sec
+		stats.UptimeSeconds = uint64(time.Now().Unix() - boot.Sec)
+	}
+
+	if memTotal, err := unix.SysctlUint64("hw.memsize"); err == nil {
+		stats.MemTotalBytes = memTotal
+	}
+	// Free memory requires a mach vm_statistics64 call that x/sys/unix
+	// doesn't expose; left at 0 rather than reporting a wrong number.
+
+	var sfs unix.Statfs_t
+	if err := unix.Statfs("/", &sfs); err == nil {
+		stats.DiskTotalBytes = uint64(sfs.Blocks) * uint64(sfs.Bsize)
+		stats.DiskFreeBytes = uint64(sfs.Bavail) * uint64(sfs.Bsize)
+	}
+
+	return stats
+}
@@ -0,0 +1,52 @@
+//go:build linux
+
+package sys
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func platformInfo() platformStats {
+	stats := platformStats{
+		CPUCount: runtime.NumCPU(),
+		Release:  linuxPrettyName(),
+	}
+
+	var si unix.Sysinfo_t
+	if err := unix.Sysinfo(&si); err == nil {
+		stats.UptimeSeconds = uint64(si.Uptime)
+		stats.BootTimeUnix = time.Now().Unix() - int64(si.Uptime)
+		unit := uint64(si.Unit)
+		if unit == 0 {
+			unit = 1
+		}
+		stats.MemTotalBytes = uint64(si.Totalram) * unit
+		stats.MemFreeBytes = uint64(si.Freeram) * unit
+	}
+
+	var sfs unix.Statfs_t
+	if err := unix.Statfs("/", &sfs); err == nil {
+		stats.DiskTotalBytes = uint64(sfs.Blocks) * uint64(sfs.Bsize)
+		stats.DiskFreeBytes = uint64(sfs.Bavail) * uint64(sfs.Bsize)
+	}
+
+	return stats
+}
+
+func linuxPrettyName() string {
+	data, err := os.ReadFile("/etc/os-release")
+	if err != nil {
+		return "Unknown"
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "PRETTY_NAME=") {
+			return strings.Trim(strings.TrimPrefix(line, "PRETTY_NAME="), "\"")
+		}
+	}
+	return "Unknown"
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package sys
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// golang.org/x/sys/windows doesn't export GetTickCount64 or
+// GlobalMemoryStatusEx (MEMORYSTATUSEX has no Go definition there either),
+// so we call into kernel32 directly the way gopsutil and similar packages
+// do.
+var (
+	modKernel32              = windows.NewLazySystemDLL("kernel32.dll")
+	procGetTickCount64       = modKernel32.NewProc("GetTickCount64")
+	procGlobalMemoryStatusEx = modKernel32.NewProc("GlobalMemoryStatusEx")
+)
+
+// memoryStatusEx mirrors the Win32 MEMORYSTATUSEX struct.
+type memoryStatusEx struct {
+	Length               uint32
+	MemoryLoad           uint32
+	TotalPhys            uint64
+	AvailPhys            uint64
+	TotalPageFile        uint64
+	AvailPageFile        uint64
+	TotalVirtual         uint64
+	AvailVirtual         uint64
+	AvailExtendedVirtual uint64
+}
+
+func platformInfo() platformStats {
+	stats := platformStats{CPUCount: runtime.NumCPU()}
+
+	if v, err := windows.GetVersion(); err == nil {
+		major := byte(v)
+		minor := byte(v >> 8)
+		build := uint16(v >> 16)
+		stats.Release = fmt.Sprintf("Windows NT %d.%d (build %d)", major, minor, build)
+	}
+
+	if r, _, _ := procGetTickCount64.Call(); r != 0 {
+		stats.UptimeSeconds = uint64(r) / 1000
+		stats.BootTimeUnix = time.Now().Unix() - int64(stats.UptimeSeconds)
+	}
+
+	var mem memoryStatusEx
+	mem.Length = uint32(unsafe.Sizeof(mem))
+	if r, _, _ := procGlobalMemoryStatusEx.Call(uintptr(unsafe.Pointer(&mem))); r != 0 {
+		stats.MemTotalBytes = mem.TotalPhys
+		stats.MemFreeBytes = mem.AvailPhys
+	}
+
+	var freeBytes, totalBytes, totalFreeBytes uint64
+	if err := windows.GetDiskFreeSpaceEx(windows.StringToUTF16Ptr(`C:\`), &freeBytes, &totalBytes, &totalFreeBytes); err == nil {
+		stats.DiskTotalBytes = totalBytes
+		stats.DiskFreeBytes = freeBytes
+	}
+
+	return stats
+}
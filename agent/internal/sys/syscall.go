@@ -1,89 +1,177 @@
+// Package sys wraps the agent's interactions with the host OS: running
+// commands and long-lived child processes, and reporting system info back
+// to the server during the agent.identify handshake. Platform-specific
+// details (uptime, kernel release, resource accounting) live in the
+// sys_<goos>.go build-tagged files; everything else is shared.
 package sys
 
 import (
 	"bytes"
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"runtime"
-	"strings"
 	"time"
 
+	"github.com/fabricore/agent/internal/security"
 	"github.com/fabricore/agent/internal/types"
 )
 
 type SystemOps interface {
 	ExecCommand(cmd string, args []string, timeout int) (string, error)
+	ExecProcess(cmd string, args []string) (*ExecHandle, error)
 	GetSystemInfo() types.OSInfo
+	Processes() *ProcessRegistry
 }
 
-type RealSystem struct{}
+type RealSystem struct {
+	log           *slog.Logger
+	processes     *ProcessRegistry
+	commandPolicy *security.CommandPolicy
+}
+
+// NewRealSystem constructs a RealSystem. commandPolicy may be nil, in which
+// case ExecCommand runs whatever it's asked to run, unconstrained; it's set
+// when the agent is started with a command allowlist configured.
+func NewRealSystem(logger *slog.Logger, commandPolicy *security.CommandPolicy) *RealSystem {
+	return &RealSystem{log: logger, processes: NewProcessRegistry(), commandPolicy: commandPolicy}
+}
 
-func NewRealSystem() *RealSystem {
-	return &RealSystem{}
+// Processes returns the registry of processes started via ExecProcess, so
+// MCP tools can list, signal, and reap them.
+func (s *RealSystem) Processes() *ProcessRegistry {
+	return s.processes
 }
 
 func (s *RealSystem) ExecCommand(cmd string, args []string, timeout int) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	command := exec.CommandContext(ctx, cmd, args...)
+	if s.commandPolicy != nil {
+		if err := s.commandPolicy.Validate(cmd, args, false); err != nil {
+			s.log.Warn("command rejected by policy", "cmd", cmd, "args", args, "error", err)
+			return "", fmt.Errorf("command rejected by policy: %w", err)
+		}
+	}
+
+	s.log.Debug("executing command", "cmd", cmd, "args", args, "timeout_s", timeout)
+
+	runCmd, runArgs, extraEnv := cmd, args, []string(nil)
+	if s.commandPolicy != nil {
+		wrappedCmd, wrappedArgs, wrappedEnv, err := s.commandPolicy.PrepareExec(cmd, args)
+		if err != nil {
+			// Best-effort, same as Prepare's RunAs: an unsupported platform
+			// shouldn't prevent an otherwise-allowed command from running,
+			// it just runs without the limits applied.
+			s.log.Warn("failed to prepare resource limits, running without them", "cmd", cmd, "error", err)
+		} else {
+			runCmd, runArgs, extraEnv = wrappedCmd, wrappedArgs, wrappedEnv
+		}
+	}
+
+	command := exec.CommandContext(ctx, runCmd, runArgs...)
+	if len(extraEnv) > 0 {
+		command.Env = append(os.Environ(), extraEnv...)
+	}
 	var stdout, stderr bytes.Buffer
 	command.Stdout = &stdout
 	command.Stderr = &stderr
 
-	err := command.Run()
+	if s.commandPolicy != nil {
+		s.commandPolicy.Prepare(command)
+	}
+
+	if err := command.Start(); err != nil {
+		s.log.Warn("command failed to start", "cmd", cmd, "error", err)
+		return "", fmt.Errorf("command failed to start: %w", err)
+	}
+
+	err := command.Wait()
 
 	if ctx.Err() == context.DeadlineExceeded {
+		s.log.Warn("command timed out", "cmd", cmd, "timeout_s", timeout)
 		return "", fmt.Errorf("command timed out after %d seconds", timeout)
 	}
 
 	if err != nil {
+		s.log.Warn("command failed", "cmd", cmd, "error", err, "stderr", stderr.String())
 		return "", fmt.Errorf("command failed: %v, stderr: %s", err, stderr.String())
 	}
 
 	return stdout.String(), nil
 }
 
-func (s *RealSystem) GetSystemInfo() types.OSInfo {
-	hostname, _ := os.Hostname()
-	uptime := getUptime()
-
-	return types.OSInfo{
-		Platform:      runtime.GOOS,
-		Hostname:      hostname,
-		Arch:          runtime.GOARCH,
-		Release:       getRelease(),
-		UptimeSeconds: uptime,
+// ExecProcess starts cmd as a long-running child process and returns a
+// handle for reading its buffered output and controlling its lifecycle (Signal,
+// Pause, Resume, Wait), instead of blocking until it exits. The process is
+// registered with Processes() until it's reaped.
+func (s *RealSystem) ExecProcess(cmd string, args []string) (*ExecHandle, error) {
+	if s.commandPolicy != nil {
+		if err := s.commandPolicy.Validate(cmd, args, false); err != nil {
+			s.log.Warn("process rejected by policy", "cmd", cmd, "args", args, "error", err)
+			return nil, fmt.Errorf("command rejected by policy: %w", err)
+		}
 	}
-}
 
-func getUptime() uint64 {
-	// Simple Linux implementation
-	data, err := os.ReadFile("/proc/uptime")
-	if err != nil {
-		return 0
+	runCmd, runArgs, extraEnv := cmd, args, []string(nil)
+	var prepare func(*exec.Cmd)
+	if s.commandPolicy != nil {
+		wrappedCmd, wrappedArgs, wrappedEnv, err := s.commandPolicy.PrepareExec(cmd, args)
+		if err != nil {
+			s.log.Warn("failed to prepare resource limits, running without them", "cmd", cmd, "error", err)
+		} else {
+			runCmd, runArgs, extraEnv = wrappedCmd, wrappedArgs, wrappedEnv
+		}
+		prepare = s.commandPolicy.Prepare
 	}
-	parts := strings.Fields(string(data))
-	if len(parts) > 0 {
-		var uptime float64
-		fmt.Sscanf(parts[0], "%f", &uptime)
-		return uint64(uptime)
+	h, err := startExecProcess(runCmd, runArgs, extraEnv, prepare)
+	if err != nil {
+		s.log.Warn("failed to start process", "cmd", cmd, "error", err)
+		return nil, err
 	}
-	return 0
+	// Report the command the caller actually asked for, not the
+	// resource-limit trampoline's rewritten argv.
+	h.Command, h.Args = cmd, args
+
+	s.log.Debug("started process", "cmd", cmd, "pid", h.Pid)
+	s.processes.add(h)
+	go func() {
+		h.Wait()
+		s.processes.remove(h.Pid)
+	}()
+	return h, nil
 }
 
-func getRelease() string {
-	// Simple Linux implementation
-	data, err := os.ReadFile("/etc/os-release")
-	if err == nil {
-		lines := strings.Split(string(data), "\n")
-		for _, line := range lines {
-			if strings.HasPrefix(line, "PRETTY_NAME=") {
-				return strings.Trim(strings.TrimPrefix(line, "PRETTY_NAME="), "\"")
-			}
-		}
+// platformStats holds the OS-specific facts GetSystemInfo reports, filled
+// in by each sys_<goos>.go's platformInfo().
+type platformStats struct {
+	Release        string
+	UptimeSeconds  uint64
+	BootTimeUnix   int64
+	CPUCount       int
+	MemTotalBytes  uint64
+	MemFreeBytes   uint64
+	DiskTotalBytes uint64
+	DiskFreeBytes  uint64
+}
+
+func (s *RealSystem) GetSystemInfo() types.OSInfo {
+	hostname, _ := os.Hostname()
+	stats := platformInfo()
+
+	return types.OSInfo{
+		Platform:       runtime.GOOS,
+		Hostname:       hostname,
+		Arch:           runtime.GOARCH,
+		Release:        stats.Release,
+		UptimeSeconds:  stats.UptimeSeconds,
+		BootTimeUnix:   stats.BootTimeUnix,
+		CPUCount:       stats.CPUCount,
+		MemTotalBytes:  stats.MemTotalBytes,
+		MemFreeBytes:   stats.MemFreeBytes,
+		DiskTotalBytes: stats.DiskTotalBytes,
+		DiskFreeBytes:  stats.DiskFreeBytes,
 	}
-	return "Unknown"
 }
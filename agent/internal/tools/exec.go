@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabricore/agent/internal/security"
+)
+
+// outputBufferLines caps how many lines of combined stdout/stderr this tool
+// retains in memory, mirroring sys.ExecHandle's bound: each line is also
+// streamed via progress as it's produced, so the retained buffer only needs
+// to cover the final "output" field in the result, not the whole lifetime
+// of a long-running or verbose command.
+const outputBufferLines = 1000
+
+// ExecCommandTool runs a command, optionally streaming its stdout/stderr
+// lines to a progress callback and honoring cancellation of the context
+// passed to ExecuteCancellable. Policy may be nil, in which case it runs
+// whatever it's asked to run, unconstrained; when set, it's evaluated the
+// same way sys.RealSystem.ExecCommand evaluates it, since tool.execute's
+// exec_command is the path a server-issued command actually takes.
+type ExecCommandTool struct {
+	Policy *security.CommandPolicy
+}
+
+func (t *ExecCommandTool) Name() string {
+	return "exec_command"
+}
+
+// Execute runs the command to completion with no streaming or
+// cancellation, for callers that only have the plain Tool interface.
+func (t *ExecCommandTool) Execute(args map[string]interface{}) (interface{}, error) {
+	return t.ExecuteCancellable(context.Background(), args, nil)
+}
+
+// ExecuteCancellable runs the command, calling progress with
+// {"stream": "stdout"|"stderr", "line": "..."} for each line of output as
+// it's produced, and aborting the command if ctx is cancelled.
+func (t *ExecCommandTool) ExecuteCancellable(ctx context.Context, args map[string]interface{}, progress func(chunk interface{})) (interface{}, error) {
+	command, _ := args["command"].(string)
+	if command == "" {
+		return nil, fmt.Errorf("missing or invalid argument 'command'")
+	}
+
+	var argv []string
+	if raw, ok := args["args"].([]interface{}); ok {
+		for _, a := range raw {
+			if s, ok := a.(string); ok {
+				argv = append(argv, s)
+			}
+		}
+	}
+
+	if timeout, ok := args["timeout"].(float64); ok && timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+		defer cancel()
+	}
+
+	runCommand, runArgv, extraEnv := command, argv, []string(nil)
+	if t.Policy != nil {
+		if err := t.Policy.Validate(command, argv, false); err != nil {
+			return nil, fmt.Errorf("command rejected by policy: %w", err)
+		}
+		if wrappedCmd, wrappedArgs, wrappedEnv, err := t.Policy.PrepareExec(command, argv); err == nil {
+			runCommand, runArgv, extraEnv = wrappedCmd, wrappedArgs, wrappedEnv
+		}
+		// Best-effort, same as sys.RealSystem.ExecCommand: an unsupported
+		// platform shouldn't prevent an otherwise-allowed command from
+		// running, it just runs without the limits applied. Unlike
+		// ExecCommand, this path does not log PrepareExec's error.
+	}
+
+	cmd := exec.CommandContext(ctx, runCommand, runArgv...)
+	if len(extraEnv) > 0 {
+		cmd.Env = append(os.Environ(), extraEnv...)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if t.Policy != nil {
+		t.Policy.Prepare(cmd)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("command failed to start: %w", err)
+	}
+
+	var (
+		outLines []string
+		outMu    sync.Mutex
+		wg       sync.WaitGroup
+	)
+
+	// bufio.Reader.ReadString is used instead of bufio.Scanner: Scanner's
+	// default 64KB max token size means a single line over that would stop
+	// the scan with no error checked here, after which nothing drains the
+	// pipe further -- a chatty child can then fill its OS pipe buffer and
+	// hang instead of exiting. ReadString has no such cap.
+	stream := func(r io.Reader, streamName string) {
+		defer wg.Done()
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				line = strings.TrimSuffix(line, "\n")
+				outMu.Lock()
+				outLines = append(outLines, line)
+				if len(outLines) > outputBufferLines {
+					outLines = outLines[len(outLines)-outputBufferLines:]
+				}
+				outMu.Unlock()
+				if progress != nil {
+					progress(map[string]string{"stream": streamName, "line": line})
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	wg.Add(2)
+	go stream(stdout, "stdout")
+	go stream(stderr, "stderr")
+	wg.Wait()
+
+	err = cmd.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("command failed: %w", err)
+	}
+
+	return map[string]string{"output": strings.Join(outLines, "\n")}, nil
+}
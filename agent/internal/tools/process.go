@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"fmt"
+	"syscall"
+
+	"github.com/fabricore/agent/internal/sys"
+)
+
+// ProcessStartTool starts a long-running child process via sys.SystemOps
+// and returns its pid, instead of blocking until it exits like
+// ExecCommandTool does. The process stays registered in Sys.Processes()
+// until it's reaped, so a later tool.execute call can list, signal, or wait
+// on it by pid.
+type ProcessStartTool struct {
+	Sys sys.SystemOps
+}
+
+func (t *ProcessStartTool) Name() string {
+	return "process_start"
+}
+
+func (t *ProcessStartTool) Execute(args map[string]interface{}) (interface{}, error) {
+	command, _ := args["command"].(string)
+	if command == "" {
+		return nil, fmt.Errorf("missing or invalid argument 'command'")
+	}
+
+	var argv []string
+	if raw, ok := args["args"].([]interface{}); ok {
+		for _, a := range raw {
+			if s, ok := a.(string); ok {
+				argv = append(argv, s)
+			}
+		}
+	}
+
+	h, err := t.Sys.ExecProcess(command, argv)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"pid": h.Pid}, nil
+}
+
+// ProcessListTool lists the processes currently registered in
+// Sys.Processes(), i.e. those started via ProcessStartTool and not yet
+// reaped.
+type ProcessListTool struct {
+	Sys sys.SystemOps
+}
+
+func (t *ProcessListTool) Name() string {
+	return "process_list"
+}
+
+func (t *ProcessListTool) Execute(args map[string]interface{}) (interface{}, error) {
+	return t.Sys.Processes().List(), nil
+}
+
+// ProcessSignalTool delivers a signal (default SIGTERM) to a registered
+// process by pid.
+type ProcessSignalTool struct {
+	Sys sys.SystemOps
+}
+
+func (t *ProcessSignalTool) Name() string {
+	return "process_signal"
+}
+
+func (t *ProcessSignalTool) Execute(args map[string]interface{}) (interface{}, error) {
+	pid, sig, err := processSignalArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.Sys.Processes().Signal(pid, sig); err != nil {
+		return nil, err
+	}
+	return map[string]string{"status": "signalled"}, nil
+}
+
+// ProcessWaitTool blocks until a registered process by pid exits, then
+// returns its exit code and buffered output.
+type ProcessWaitTool struct {
+	Sys sys.SystemOps
+}
+
+func (t *ProcessWaitTool) Name() string {
+	return "process_wait"
+}
+
+func (t *ProcessWaitTool) Execute(args map[string]interface{}) (interface{}, error) {
+	pidF, ok := args["pid"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid argument 'pid'")
+	}
+	pid := int(pidF)
+
+	h, ok := t.Sys.Processes().Get(pid)
+	if !ok {
+		return nil, fmt.Errorf("no registered process with pid %d", pid)
+	}
+	waitErr := h.Wait()
+
+	result := map[string]interface{}{
+		"exit_code": h.ExitCode(),
+		"stdout":    h.Stdout(),
+		"stderr":    h.Stderr(),
+	}
+	if waitErr != nil {
+		result["error"] = waitErr.Error()
+	}
+	return result, nil
+}
+
+func processSignalArgs(args map[string]interface{}) (int, syscall.Signal, error) {
+	pidF, ok := args["pid"].(float64)
+	if !ok {
+		return 0, 0, fmt.Errorf("missing or invalid argument 'pid'")
+	}
+
+	name, _ := args["signal"].(string)
+	switch name {
+	case "", "SIGTERM":
+		return int(pidF), syscall.SIGTERM, nil
+	case "SIGKILL":
+		return int(pidF), syscall.SIGKILL, nil
+	case "SIGINT":
+		return int(pidF), syscall.SIGINT, nil
+	case "SIGHUP":
+		return int(pidF), syscall.SIGHUP, nil
+	default:
+		return 0, 0, fmt.Errorf("unsupported signal %q", name)
+	}
+}
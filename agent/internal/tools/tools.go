@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"sync"
 )
@@ -11,6 +12,16 @@ type Tool interface {
 	Execute(args map[string]interface{}) (interface{}, error)
 }
 
+// CancellableTool is implemented by tools that can be aborted mid-run and
+// can stream intermediate output. Registry.ExecuteTool prefers
+// ExecuteCancellable over the plain Tool.Execute whenever a tool implements
+// it, passing a progress callback the tool may invoke any number of times
+// before it returns.
+type CancellableTool interface {
+	Tool
+	ExecuteCancellable(ctx context.Context, args map[string]interface{}, progress func(chunk interface{})) (interface{}, error)
+}
+
 // Registry manages the available tools.
 type Registry struct {
 	tools map[string]Tool
@@ -53,11 +64,16 @@ func (r *Registry) ToolList() []string {
 	return names
 }
 
-// ExecuteTool helper to find and execute a tool safely.
-func (r *Registry) ExecuteTool(name string, args map[string]interface{}) (interface{}, error) {
+// ExecuteTool finds and runs a tool, preferring its CancellableTool form
+// (cancellable via ctx, streaming through progress) when it implements one.
+// progress may be nil; plain Tool implementations never see it.
+func (r *Registry) ExecuteTool(ctx context.Context, name string, args map[string]interface{}, progress func(chunk interface{})) (interface{}, error) {
 	tool, err := r.GetTool(name)
 	if err != nil {
 		return nil, err
 	}
+	if ct, ok := tool.(CancellableTool); ok {
+		return ct.ExecuteCancellable(ctx, args, progress)
+	}
 	return tool.Execute(args)
 }
@@ -9,12 +9,48 @@ const (
 	JSONRPCVersion = "2.0"
 )
 
-// JSONRPCRequest represents a JSON-RPC 2.0 request
+// JSONRPCRequest represents a JSON-RPC 2.0 request. A nil ID means the
+// request is a notification; the "id" field's omitempty tag drops the key
+// entirely in that case, rather than encoding it as JSON null, so a
+// spec-compliant peer never expects a reply.
 type JSONRPCRequest struct {
 	JSONRPC string          `json:"jsonrpc"`
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
-	ID      interface{}     `json:"id"` // string or int
+	ID      interface{}     `json:"id,omitempty"` // string or int; nil for notifications
+}
+
+// Notification builds a JSONRPCRequest with no id.
+func Notification(method string, params json.RawMessage) JSONRPCRequest {
+	return JSONRPCRequest{JSONRPC: JSONRPCVersion, Method: method, Params: params}
+}
+
+// IDsEqual reports whether two JSON-RPC ids refer to the same request. It
+// compares both type and value, since the spec treats the string "1" and
+// the number 1 as distinct ids, and json.Unmarshal always decodes numeric
+// ids into float64.
+func IDsEqual(a, b interface{}) bool {
+	an, aIsNum := normalizeID(a)
+	bn, bIsNum := normalizeID(b)
+	if aIsNum || bIsNum {
+		return aIsNum == bIsNum && an == bn
+	}
+	return a == b
+}
+
+func normalizeID(id interface{}) (float64, bool) {
+	switch v := id.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
 }
 
 // JSONRPCResponse represents a JSON-RPC 2.0 response
@@ -47,11 +83,17 @@ type AgentIdentity struct {
 }
 
 type OSInfo struct {
-	Platform      string `json:"platform"`
-	Hostname      string `json:"hostname"`
-	Arch          string `json:"arch"`
-	Release       string `json:"release"`
-	UptimeSeconds uint64 `json:"uptime_seconds"`
+	Platform       string `json:"platform"`
+	Hostname       string `json:"hostname"`
+	Arch           string `json:"arch"`
+	Release        string `json:"release"`
+	UptimeSeconds  uint64 `json:"uptime_seconds"`
+	BootTimeUnix   int64  `json:"boot_time_unix"`
+	CPUCount       int    `json:"cpu_count"`
+	MemTotalBytes  uint64 `json:"mem_total_bytes"`
+	MemFreeBytes   uint64 `json:"mem_free_bytes"`
+	DiskTotalBytes uint64 `json:"disk_total_bytes"`
+	DiskFreeBytes  uint64 `json:"disk_free_bytes"`
 }
 
 type Capabilities struct {
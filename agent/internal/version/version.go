@@ -0,0 +1,8 @@
+// Package version holds the agent build version, surfaced in the startup
+// banner and the diagnostic /version endpoint.
+package version
+
+// Version is the agent's release version. It's a plain constant rather than
+// an -ldflags-injected variable until the build actually produces versioned
+// releases.
+const Version = "0.1.0"